@@ -0,0 +1,150 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// elementReadSlack is how far past ArrayLimits.MaxElementSize the decoder may read
+// ahead, covering separators and whitespace between elements.
+const elementReadSlack = 512
+
+// ArrayLimits bounds the work done by ReadJSONArray. Zero values mean no limit.
+type ArrayLimits struct {
+	MaxElements    int   // maximum number of elements in the array
+	MaxElementSize int64 // maximum size in bytes of a single encoded element
+}
+
+// ElementError reports a failure to decode a single element of a JSON array.
+type ElementError struct {
+	Index int // 0-based index of the element in the array
+	Err   error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("json array element %d: %v", e.Index, e.Err)
+}
+
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// ReadJSONArray walks a top-level JSON array token by token, yielding each element decoded into T.
+// Only one element is held in memory at a time. Elements are decoded with UnMarshal,
+// so unknown fields are rejected; such failures yield an *ElementError and iteration continues.
+// Syntax errors, read errors and exceeded limits are yielded once and end the iteration,
+// malformed input as a *DecodeError like UnMarshal reports it.
+func ReadJSONArray[T any](r io.Reader, limits ArrayLimits) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		lr := &elementLimitReader{r: r, limit: -1}
+		decoder := json.NewDecoder(lr)
+
+		token, err := decoder.Token()
+		if err != nil {
+			yield(zero, wrapDecodeError(err, nil))
+			return
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			yield(zero, &DecodeError{Err: ErrNotArray})
+			return
+		}
+
+		for index := 0; decoder.More(); index++ {
+			if limits.MaxElements > 0 && index >= limits.MaxElements {
+				yield(zero, ErrTooManyElements)
+				return
+			}
+
+			if limits.MaxElementSize > 0 {
+				lr.limit = decoder.InputOffset() + limits.MaxElementSize + elementReadSlack
+			}
+
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				if errors.Is(err, ErrElementTooLarge) {
+					err = &ElementError{Index: index, Err: ErrElementTooLarge}
+				} else {
+					err = wrapDecodeError(err, nil)
+				}
+				yield(zero, err)
+				return
+			}
+			lr.limit = -1
+
+			if limits.MaxElementSize > 0 && int64(len(raw)) > limits.MaxElementSize {
+				yield(zero, &ElementError{Index: index, Err: ErrElementTooLarge})
+				return
+			}
+
+			var v T
+			if err := UnMarshal(raw, &v); err != nil {
+				if !yield(zero, &ElementError{Index: index, Err: err}) {
+					return
+				}
+				continue
+			}
+
+			if !yield(v, nil) {
+				return
+			}
+		}
+
+		// Consume the closing bracket
+		if _, err := decoder.Token(); err != nil {
+			yield(zero, wrapDecodeError(err, nil))
+			return
+		}
+
+		// 🚨 Check for leftover data
+		if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+			yield(zero, wrapDecodeError(ErrExtraData, nil))
+		}
+	}
+}
+
+// BindRequestArray streams a JSON array request body, see ReadJSONArray.
+// Compressed bodies are decoded like BindRequestBody does.
+func BindRequestArray[T any](r *http.Request, limits ArrayLimits) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		body, err := defaultCodec.requestBody(r)
+		if err != nil {
+			var zero T
+			yield(zero, markRequestError(err))
+			return
+		}
+		for v, err := range ReadJSONArray[T](body, limits) {
+			if !yield(v, markRequestError(err)) {
+				return
+			}
+		}
+	}
+}
+
+// elementLimitReader stops reading once the absolute read position reaches limit,
+// so an oversized element cannot make the decoder buffer it entirely.
+type elementLimitReader struct {
+	r     io.Reader
+	read  int64
+	limit int64 // negative means unlimited
+}
+
+func (l *elementLimitReader) Read(p []byte) (int, error) {
+	if l.limit >= 0 {
+		remaining := l.limit - l.read
+		if remaining <= 0 {
+			return 0, ErrElementTooLarge
+		}
+		if int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,154 @@
+package jsonkit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+)
+
+type ArraySuite struct {
+	suite.Suite
+}
+
+func (s *ArraySuite) collect(body string, limits jsonkit.ArrayLimits) ([]string, []error) {
+	var names []string
+	var errs []error
+	for user, err := range jsonkit.ReadJSONArray[*pb.User](strings.NewReader(body), limits) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, user.Name)
+	}
+	return names, errs
+}
+
+func (s *ArraySuite) TestReadJSONArray_Success() {
+	names, errs := s.collect(`[{"name": "John"}, {"name": "Jane"}]`, jsonkit.ArrayLimits{})
+
+	s.Empty(errs)
+	s.Equal([]string{"John", "Jane"}, names)
+}
+
+func (s *ArraySuite) TestReadJSONArray_Empty() {
+	names, errs := s.collect(` [ ] `, jsonkit.ArrayLimits{})
+
+	s.Empty(errs)
+	s.Empty(names)
+}
+
+func (s *ArraySuite) TestReadJSONArray_NotArray() {
+	_, errs := s.collect(`{"name": "John"}`, jsonkit.ArrayLimits{})
+
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrNotArray)
+}
+
+func (s *ArraySuite) TestReadJSONArray_ElementError() {
+	names, errs := s.collect(`[{"name": "John"}, {"extra": 1}, {"name": "Bob"}]`, jsonkit.ArrayLimits{})
+
+	s.Equal([]string{"John", "Bob"}, names)
+	s.Len(errs, 1)
+	var elemErr *jsonkit.ElementError
+	s.True(errors.As(errs[0], &elemErr))
+	s.Equal(1, elemErr.Index)
+}
+
+func (s *ArraySuite) TestReadJSONArray_SyntaxError() {
+	names, errs := s.collect(`[{"name": "John"}, {"name": `, jsonkit.ArrayLimits{})
+
+	s.Equal([]string{"John"}, names)
+	s.Len(errs, 1)
+}
+
+func (s *ArraySuite) TestReadJSONArray_ExtraData() {
+	names, errs := s.collect(`[{"name": "John"}] []`, jsonkit.ArrayLimits{})
+
+	s.Equal([]string{"John"}, names)
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrExtraData)
+}
+
+func (s *ArraySuite) TestReadJSONArray_TooManyElements() {
+	names, errs := s.collect(`[{"name": "a"}, {"name": "b"}, {"name": "c"}]`, jsonkit.ArrayLimits{MaxElements: 2})
+
+	s.Equal([]string{"a", "b"}, names)
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrTooManyElements)
+}
+
+func (s *ArraySuite) TestReadJSONArray_ElementTooLarge() {
+	body := `[{"name": "a"}, {"name": "` + strings.Repeat("x", 100) + `"}]`
+
+	names, errs := s.collect(body, jsonkit.ArrayLimits{MaxElementSize: 50})
+
+	s.Equal([]string{"a"}, names)
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrElementTooLarge)
+}
+
+func (s *ArraySuite) TestReadJSONArray_ElementTooLarge_StopsReading() {
+	body := `[{"name": "` + strings.Repeat("x", 1<<20) + `"}]`
+	r := strings.NewReader(body)
+
+	var errs []error
+	for _, err := range jsonkit.ReadJSONArray[*pb.User](r, jsonkit.ArrayLimits{MaxElementSize: 1024}) {
+		errs = append(errs, err)
+	}
+
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrElementTooLarge)
+	// The oversized element must not have been buffered entirely
+	s.Greater(r.Len(), 1<<19)
+}
+
+func (s *ArraySuite) TestBindRequestArray_Success() {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[{"name": "John", "age": 30}]`))
+
+	var users []*pb.User
+	for user, err := range jsonkit.BindRequestArray[*pb.User](req, jsonkit.ArrayLimits{MaxElements: 10}) {
+		s.Nil(err)
+		users = append(users, user)
+	}
+
+	s.Len(users, 1)
+	s.Equal(int32(30), users[0].Age)
+}
+
+func (s *ArraySuite) TestBindRequestArray_ErrorStatus() {
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`[{"name": "John"}, {"name": `, http.StatusBadRequest},
+		{`[{"name": "John"} {"name": "Jane"}]`, http.StatusBadRequest},
+		{`{"name": "John"}`, http.StatusBadRequest},
+		{`[{"name": "John"}] []`, http.StatusBadRequest},
+		{`[{"extra": 1}]`, http.StatusBadRequest},
+		{`[{"name": "John"}, {"name": "Jane"}, {"name": "Bob"}]`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.body))
+
+		var errs []error
+		for _, err := range jsonkit.BindRequestArray[*pb.User](req, jsonkit.ArrayLimits{MaxElements: 2}) {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		s.Len(errs, 1, c.body)
+		s.Equal(c.status, jsonkit.ErrorStatus(errs[0]), c.body)
+	}
+}
+
+func TestArraySuite(t *testing.T) {
+	suite.Run(t, new(ArraySuite))
+}
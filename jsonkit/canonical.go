@@ -0,0 +1,179 @@
+package jsonkit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// MarshalCanonical encodes v following the RFC 8785 JSON Canonicalization Scheme:
+// object keys sorted by UTF-16 code units, numbers serialized like ECMAScript
+// and strings with minimal escaping. Equal values always produce identical bytes,
+// which makes the output suitable for signing and hashing.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(data)
+}
+
+// Canonicalize rewrites a raw JSON document in its RFC 8785 canonical form.
+func Canonicalize(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := documentCodec.UnMarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalDigest writes the canonical form of v to h and returns the resulting digest.
+func CanonicalDigest(v interface{}, h hash.Hash) ([]byte, error) {
+	data, err := MarshalCanonical(v)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Reset()
+	h.Write(data) // hash.Hash never returns an error
+	return h.Sum(nil), nil
+}
+
+// CanonicalSHA256 returns the hex encoded SHA-256 digest of the canonical form of v.
+func CanonicalSHA256(v interface{}) (string, error) {
+	digest, err := CanonicalDigest(v, sha256.New())
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		writeCanonicalString(buf, x)
+	case json.Number:
+		f, err := strconv.ParseFloat(x.String(), 64)
+		if err != nil {
+			return fmt.Errorf("jsonkit: number %s cannot be canonicalized: %w", x, err)
+		}
+		number, err := formatES6Number(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, value := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, compareUTF16)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, x[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("jsonkit: unexpected %T in decoded document", v)
+	}
+	return nil
+}
+
+// formatES6Number serializes f like ECMAScript Number.prototype.toString.
+func formatES6Number(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("jsonkit: %v cannot be represented in JSON", f)
+	}
+	if f == 0 {
+		// Also covers -0
+		return "0", nil
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Go pads the exponent to two digits, ECMAScript doesn't: 1e-07 -> 1e-7
+		mantissa, exponent, _ := strings.Cut(s, "e")
+		sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+		s = mantissa + "e" + sign + digits
+	}
+	return s, nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// compareUTF16 orders strings by their UTF-16 code units as required by RFC 8785.
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
@@ -0,0 +1,104 @@
+package jsonkit_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+type CanonicalSuite struct {
+	suite.Suite
+}
+
+func (s *CanonicalSuite) TestCanonicalize_RFCExample() {
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+
+	result, err := jsonkit.Canonicalize([]byte(input))
+
+	s.Nil(err)
+	s.Equal(`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(result))
+}
+
+func (s *CanonicalSuite) TestCanonicalize_KeyOrder() {
+	input := `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`
+
+	result, err := jsonkit.Canonicalize([]byte(input))
+
+	s.Nil(err)
+	s.Equal("{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(result))
+}
+
+func (s *CanonicalSuite) TestCanonicalize_Numbers() {
+	cases := map[string]string{
+		"0":                      "0",
+		"-0":                     "0",
+		"1":                      "1",
+		"-1.5":                   "-1.5",
+		"1e21":                   "1e+21",
+		"1e20":                   "100000000000000000000",
+		"0.000001":               "0.000001",
+		"0.0000001":              "1e-7",
+		"123e-20":                "1.23e-18",
+		"9007199254740993":       "9007199254740992",
+		"1.7976931348623157e308": "1.7976931348623157e+308",
+		"5e-324":                 "5e-324",
+	}
+
+	for input, expected := range cases {
+		result, err := jsonkit.Canonicalize([]byte(input))
+		s.Nil(err, input)
+		s.Equal(expected, string(result), input)
+	}
+}
+
+func (s *CanonicalSuite) TestCanonicalize_NumberOutOfRange() {
+	_, err := jsonkit.Canonicalize([]byte(`1e400`))
+
+	s.NotNil(err)
+}
+
+func (s *CanonicalSuite) TestMarshalCanonical_NoHTMLEscaping() {
+	result, err := jsonkit.MarshalCanonical(map[string]interface{}{"b": "<a&b>", "a": []int{1, 2}})
+
+	s.Nil(err)
+	s.Equal(`{"a":[1,2],"b":"<a&b>"}`, string(result))
+}
+
+func (s *CanonicalSuite) TestMarshalCanonical_StructAndMapAgree() {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	fromStruct, err := jsonkit.MarshalCanonical(user{Name: "John", Age: 30})
+	s.Nil(err)
+	fromMap, err := jsonkit.MarshalCanonical(map[string]interface{}{"name": "John", "age": 30.0})
+	s.Nil(err)
+
+	s.Equal(string(fromStruct), string(fromMap))
+}
+
+func (s *CanonicalSuite) TestCanonicalSHA256() {
+	digest, err := jsonkit.CanonicalSHA256(map[string]int{"b": 2, "a": 1})
+	s.Nil(err)
+
+	expected := sha256.Sum256([]byte(`{"a":1,"b":2}`))
+	s.Equal(hex.EncodeToString(expected[:]), digest)
+}
+
+func (s *CanonicalSuite) TestCanonicalDigest_Error() {
+	_, err := jsonkit.CanonicalDigest(make(chan int), sha256.New())
+
+	s.NotNil(err)
+}
+
+func TestCanonicalSuite(t *testing.T) {
+	suite.Run(t, new(CanonicalSuite))
+}
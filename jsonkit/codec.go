@@ -0,0 +1,345 @@
+package jsonkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec holds the encoding policy used by the jsonkit helpers.
+// The package level functions use a Codec created by NewCodec,
+// services needing a different policy create their own and call its methods.
+//
+// The decoding policy applies to Go values and Protobuf messages alike: DisallowUnknownFields
+// rejects unknown fields in JSON, binary and text Protobuf input and discards them otherwise,
+// trailing JSON data fails with ErrExtraData and MaxDepth bounds the nesting of JSON and binary input.
+type Codec struct {
+	EscapeHTML            bool   // escape <, > and & in JSON strings
+	Prefix                string // line prefix used when Indent is set
+	Indent                string // indentation per level, empty for compact output
+	TrailingNewline       bool   // terminate marshaled JSON with a newline
+	DisallowUnknownFields bool   // reject object keys and Protobuf fields that don't match the target
+	UseNumber             bool   // decode numbers into interface{} as json.Number instead of float64
+	ValidateSchema        bool   // validate documents against the JSON Schema of the target type before decoding
+	MaxBodyBytes          int64  // maximum request body size accepted by the Bind helpers, 0 for no limit
+	MaxDepth              int    // maximum nesting of JSON arrays and objects or Protobuf messages, 0 for the decoder defaults
+	MaxDecompressedBytes  int64  // maximum size of a gzip or deflate request body once decoded, 0 for no limit
+	CompressMinSize       int    // smallest response compressed by the Compress middleware
+
+	ProtoMarshalOptions protojson.MarshalOptions
+	// ProtoUnmarshalOptions is used for protojson input. DiscardUnknown is implied when
+	// DisallowUnknownFields is off, and MaxDepth overrides RecursionLimit when set.
+	ProtoUnmarshalOptions protojson.UnmarshalOptions
+}
+
+var defaultCodec = NewCodec()
+
+// NewCodec creates a Codec with the default jsonkit policy:
+// HTML escaping on, trailing newline appended, unknown fields rejected,
+// compression limits at their defaults and protobuf messages marshaled with unpopulated fields.
+func NewCodec() *Codec {
+	return &Codec{
+		EscapeHTML:            true,
+		TrailingNewline:       true,
+		DisallowUnknownFields: true,
+		MaxDecompressedBytes:  DefaultMaxDecompressedBytes,
+		CompressMinSize:       DefaultCompressMinSize,
+		ProtoMarshalOptions: protojson.MarshalOptions{
+			EmitUnpopulated:   true,
+			EmitDefaultValues: false,
+		},
+	}
+}
+
+// Marshal encodes v to JSON according to the codec policy.
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(c.EscapeHTML)
+	encoder.SetIndent(c.Prefix, c.Indent)
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	if !c.TrailingNewline {
+		data = bytes.TrimSuffix(data, []byte("\n"))
+	}
+	return data, nil
+}
+
+// UnMarshal decodes a single JSON document into v according to the codec policy.
+func (c *Codec) UnMarshal(data []byte, v interface{}) error {
+	return c.decode(bytes.NewReader(data), v)
+}
+
+// MarshalProto encodes a Protobuf message to JSON using ProtoMarshalOptions.
+func (c *Codec) MarshalProto(v proto.Message) ([]byte, error) {
+	return c.ProtoMarshalOptions.Marshal(v)
+}
+
+// UnMarshalProto decodes JSON into a Protobuf message using ProtoUnmarshalOptions
+// and the codec strictness policy.
+func (c *Codec) UnMarshalProto(data []byte, v proto.Message) error {
+	if c.MaxDepth > 0 {
+		if err := checkDepth(data, c.MaxDepth); err != nil {
+			return &DecodeError{Err: err}
+		}
+	}
+
+	if err := c.protoJSONOptions().Unmarshal(data, v); err != nil {
+		// protojson reports trailing data as a syntax error, report it like UnMarshal does
+		if hasExtraData(data) {
+			err = ErrExtraData
+		}
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+// protoJSONOptions merges the codec strictness policy into ProtoUnmarshalOptions.
+func (c *Codec) protoJSONOptions() protojson.UnmarshalOptions {
+	opts := c.ProtoUnmarshalOptions
+	opts.DiscardUnknown = c.discardUnknown()
+	if c.MaxDepth > 0 {
+		opts.RecursionLimit = c.MaxDepth
+	}
+	return opts
+}
+
+// discardUnknown reports whether unknown Protobuf fields are dropped rather than rejected.
+func (c *Codec) discardUnknown() bool {
+	return !c.DisallowUnknownFields || c.ProtoUnmarshalOptions.DiscardUnknown
+}
+
+// BindRequestBody decodes the JSON request body into v.
+func (c *Codec) BindRequestBody(r *http.Request, v interface{}) error {
+	body, err := c.requestBody(r)
+	if err != nil {
+		return markRequestError(err)
+	}
+
+	err = c.decode(body, v)
+	if contentEncoded(r) && (err == nil || errors.Is(err, ErrExtraData)) {
+		// The decoder stops at the end of the document, read the rest of the compressed stream
+		// so a truncated stream or a checksum mismatch isn't taken for a valid body
+		if _, drainErr := io.Copy(io.Discard, body); drainErr != nil {
+			err = drainErr
+		}
+	}
+	return markRequestError(err)
+}
+
+// JSONResponse writes a Go struct as JSON to the response.
+func (c *Codec) JSONResponse(w http.ResponseWriter, statusCode int, v interface{}) error {
+	bytes, err := c.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(statusCode)
+	_, err = w.Write(bytes)
+	return err
+}
+
+// BindProtoRequestBody decodes JSON from the request body into a Protobuf message.
+func (c *Codec) BindProtoRequestBody(r *http.Request, v proto.Message) error {
+	data, err := c.readBody(r)
+	if err != nil {
+		return markRequestError(err)
+	}
+
+	// Unmarshal Protobuf JSON
+	return markRequestError(c.UnMarshalProto(data, v))
+}
+
+// ProtoJSONResponse writes a Protobuf message as JSON to the response.
+func (c *Codec) ProtoJSONResponse(w http.ResponseWriter, statusCode int, v proto.Message) error {
+	data, err := c.MarshalProto(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(statusCode)
+	_, err = w.Write(data)
+	return err
+}
+
+// readBody reads the entire request body within MaxBodyBytes, decoding its Content-Encoding,
+// and restores it so it can be read again later.
+func (c *Codec) readBody(r *http.Request) ([]byte, error) {
+	body, err := c.requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The restored body is decoded already
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.Header.Del("Content-Encoding")
+	r.ContentLength = int64(len(data))
+	return data, nil
+}
+
+// limitBody makes reads fail with ErrBodyTooLarge once more than MaxBodyBytes are read.
+func (c *Codec) limitBody(body io.Reader) io.Reader {
+	if c.MaxBodyBytes <= 0 {
+		return body
+	}
+	return &bodyLimitReader{r: body, remaining: c.MaxBodyBytes}
+}
+
+type bodyLimitReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *bodyLimitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	// Read one byte past the limit to tell a body of exactly MaxBodyBytes from a larger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// depthLimitReader makes reads fail with ErrMaxDepth once JSON arrays and objects nest deeper than max.
+// It tracks strings across reads so brackets inside them aren't counted.
+type depthLimitReader struct {
+	r        io.Reader
+	max      int
+	depth    int
+	inString bool
+	escaped  bool
+	exceeded bool
+}
+
+func newDepthLimitReader(r io.Reader, max int) *depthLimitReader {
+	return &depthLimitReader{r: r, max: max}
+}
+
+func (d *depthLimitReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	for i, b := range p[:n] {
+		switch {
+		case d.escaped:
+			d.escaped = false
+		case d.inString:
+			switch b {
+			case '\\':
+				d.escaped = true
+			case '"':
+				d.inString = false
+			}
+		case b == '"':
+			d.inString = true
+		case b == '{' || b == '[':
+			d.depth++
+			if d.depth > d.max {
+				// hold back the offending bracket so the decoder can't complete the value
+				d.exceeded = true
+				return i, ErrMaxDepth
+			}
+		case b == '}' || b == ']':
+			d.depth--
+		}
+	}
+	return n, err
+}
+
+// checkDepth scans data for arrays and objects nested deeper than max.
+func checkDepth(data []byte, max int) error {
+	_, err := io.Copy(io.Discard, newDepthLimitReader(bytes.NewReader(data), max))
+	return err
+}
+
+// hasExtraData reports whether data holds a single valid JSON value followed by more input.
+func hasExtraData(data []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var value json.RawMessage
+	if err := decoder.Decode(&value); err != nil {
+		return false
+	}
+	_, err := decoder.Token()
+	return err != io.EOF
+}
+
+func (c *Codec) decode(r io.Reader, v interface{}) error {
+	var depthLimit *depthLimitReader
+	if c.MaxDepth > 0 {
+		depthLimit = newDepthLimitReader(r, c.MaxDepth)
+		r = depthLimit
+	}
+
+	if c.ValidateSchema {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return wrapDecodeError(err, v)
+		}
+		if err := validateTarget(data, v); err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+
+	decoder := json.NewDecoder(r)
+	if c.DisallowUnknownFields {
+		decoder.DisallowUnknownFields() // Prevents extra unknown fields
+	}
+	if c.UseNumber {
+		decoder.UseNumber()
+	}
+
+	// Decode the JSON into the target struct
+	if err := decoder.Decode(v); err != nil {
+		// the decoder may report the failed read as a truncated document
+		if depthLimit != nil && depthLimit.exceeded {
+			err = ErrMaxDepth
+		}
+		return wrapDecodeError(err, v)
+	}
+
+	// 🚨 Check for leftover data
+	if decoder.More() {
+		return wrapDecodeError(ErrExtraData, nil)
+	}
+
+	return nil
+}
+
+// validateTarget validates data against the schema generated for the type v points to.
+func validateTarget(data []byte, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schema, err := cachedSchemaFor(t)
+	if err != nil {
+		return err
+	}
+	return schema.ValidateJSON(data)
+}
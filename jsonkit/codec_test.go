@@ -0,0 +1,226 @@
+package jsonkit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+)
+
+type CodecSuite struct {
+	suite.Suite
+}
+
+func (s *CodecSuite) TestNewCodec_MatchesPackageFunctions() {
+	codec := jsonkit.NewCodec()
+	v := map[string]string{"html": "<b>"}
+
+	expected, err := jsonkit.Marshal(v)
+	s.Nil(err)
+	data, err := codec.Marshal(v)
+	s.Nil(err)
+
+	s.Equal(string(expected), string(data))
+	s.Equal(`{"html":"\u003cb\u003e"}`+"\n", string(data))
+}
+
+func (s *CodecSuite) TestMarshal_NoEscapeHTML_NoTrailingNewline() {
+	codec := jsonkit.NewCodec()
+	codec.EscapeHTML = false
+	codec.TrailingNewline = false
+
+	data, err := codec.Marshal(map[string]string{"html": "<b>"})
+
+	s.Nil(err)
+	s.Equal(`{"html":"<b>"}`, string(data))
+}
+
+func (s *CodecSuite) TestMarshal_Indent() {
+	codec := jsonkit.NewCodec()
+	codec.Indent = "  "
+	codec.TrailingNewline = false
+
+	data, err := codec.Marshal(map[string]int{"age": 30})
+
+	s.Nil(err)
+	s.Equal("{\n  \"age\": 30\n}", string(data))
+}
+
+func (s *CodecSuite) TestUnMarshal_AllowUnknownFields() {
+	codec := jsonkit.NewCodec()
+	codec.DisallowUnknownFields = false
+
+	var user pb.User
+	err := codec.UnMarshal([]byte(`{"name": "John", "extra": "field"}`), &user)
+
+	s.Nil(err)
+	s.Equal("John", user.Name)
+}
+
+func (s *CodecSuite) TestUnMarshal_UseNumber() {
+	codec := jsonkit.NewCodec()
+	codec.UseNumber = true
+
+	var v map[string]interface{}
+	err := codec.UnMarshal([]byte(`{"id": 12345678901234567890}`), &v)
+
+	s.Nil(err)
+	s.Equal(json.Number("12345678901234567890"), v["id"])
+}
+
+func (s *CodecSuite) TestUnMarshal_ExtraData() {
+	codec := jsonkit.NewCodec()
+
+	var user pb.User
+	err := codec.UnMarshal([]byte(`{"name": "John"} {"name": "Jane"}`), &user)
+
+	s.ErrorIs(err, jsonkit.ErrExtraData)
+}
+
+func (s *CodecSuite) TestMarshalProto_Options() {
+	codec := jsonkit.NewCodec()
+	codec.ProtoMarshalOptions.EmitUnpopulated = false
+
+	data, err := codec.MarshalProto(&pb.User{Name: "John"})
+
+	s.Nil(err)
+	s.JSONEq(`{"name":"John"}`, string(data))
+}
+
+func (s *CodecSuite) TestUnMarshalProto_DiscardUnknown() {
+	codec := jsonkit.NewCodec()
+	codec.ProtoUnmarshalOptions.DiscardUnknown = true
+
+	var user pb.User
+	err := codec.UnMarshalProto([]byte(`{"name": "John", "extra": "field"}`), &user)
+
+	s.Nil(err)
+	s.Equal("John", user.Name)
+}
+
+func (s *CodecSuite) TestUnMarshalProto_MatchesUnMarshalPolicy() {
+	cases := []struct {
+		data     string
+		expected error
+	}{
+		{`{"name": "John"} {"name": "Jane"}`, jsonkit.ErrExtraData},
+		{`{"name": "John"} x`, jsonkit.ErrExtraData},
+		{`{"name": "John", "extra": "field"}`, nil},
+		{`{"name": "John"`, nil},
+	}
+
+	for _, c := range cases {
+		var decodeErr *jsonkit.DecodeError
+
+		err := jsonkit.UnMarshal([]byte(c.data), &pb.User{})
+		s.ErrorAs(err, &decodeErr, c.data)
+		protoErr := jsonkit.UnMarshalProto([]byte(c.data), &pb.User{})
+		s.ErrorAs(protoErr, &decodeErr, c.data)
+
+		if c.expected != nil {
+			s.ErrorIs(err, c.expected, c.data)
+			s.ErrorIs(protoErr, c.expected, c.data)
+		}
+	}
+}
+
+func (s *CodecSuite) TestUnMarshalProto_AllowUnknownFields() {
+	codec := jsonkit.NewCodec()
+	codec.DisallowUnknownFields = false
+
+	var user pb.User
+	err := codec.UnMarshalProto([]byte(`{"name": "John", "extra": {"nested": [1]}}`), &user)
+
+	s.Nil(err)
+	s.Equal("John", user.Name)
+}
+
+func (s *CodecSuite) TestMaxDepth() {
+	codec := jsonkit.NewCodec()
+	codec.DisallowUnknownFields = false
+	codec.MaxDepth = 3
+
+	shallow := []byte(`{"name": "[[[[", "extra": [[1]]}`)
+	deep := []byte(`{"name": "John", "extra": [[[1]]]}`)
+
+	s.Nil(codec.UnMarshal(shallow, &pb.User{}))
+	s.Nil(codec.UnMarshalProto(shallow, &pb.User{}))
+
+	var decodeErr *jsonkit.DecodeError
+	err := codec.UnMarshal(deep, &pb.User{})
+	s.ErrorIs(err, jsonkit.ErrMaxDepth)
+	s.ErrorAs(err, &decodeErr)
+
+	err = codec.UnMarshalProto(deep, &pb.User{})
+	s.ErrorIs(err, jsonkit.ErrMaxDepth)
+	s.ErrorAs(err, &decodeErr)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(deep))
+	s.ErrorIs(codec.BindRequestBody(req, &pb.User{}), jsonkit.ErrMaxDepth)
+}
+
+func (s *CodecSuite) TestBindRequestBody_AllowUnknownFields() {
+	codec := jsonkit.NewCodec()
+	codec.DisallowUnknownFields = false
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "John", "extra": "field"}`))
+
+	var user pb.User
+	err := codec.BindRequestBody(req, &user)
+
+	s.Nil(err)
+	s.Equal("John", user.Name)
+}
+
+func (s *CodecSuite) TestJSONResponse_Indent() {
+	codec := jsonkit.NewCodec()
+	codec.Indent = "\t"
+	w := httptest.NewRecorder()
+
+	err := codec.JSONResponse(w, http.StatusOK, map[string]int{"age": 30})
+
+	s.Nil(err)
+	s.Equal("application/json", w.Header().Get("Content-Type"))
+	s.Equal("{\n\t\"age\": 30\n}\n", w.Body.String())
+}
+
+func (s *CodecSuite) TestBindRequestBody_MaxBodyBytes() {
+	codec := jsonkit.NewCodec()
+	codec.MaxBodyBytes = 16
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "John"}`))
+	var user pb.User
+	s.Nil(codec.BindRequestBody(req, &user))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": "Johnny"}`))
+	err := codec.BindRequestBody(req, &user)
+	s.ErrorIs(err, jsonkit.ErrBodyTooLarge)
+}
+
+func (s *CodecSuite) TestUnMarshal_DecodeError() {
+	cases := []string{
+		`{"name": "John"`,
+		`{"name": "John", "extra": 1}`,
+		`{"name": 1}`,
+		`{"name": "John"} {}`,
+		``,
+	}
+
+	for _, c := range cases {
+		var user pb.User
+		err := jsonkit.UnMarshal([]byte(c), &user)
+
+		var decodeErr *jsonkit.DecodeError
+		s.True(errors.As(err, &decodeErr), c)
+	}
+}
+
+func TestCodecSuite(t *testing.T) {
+	suite.Run(t, new(CodecSuite))
+}
@@ -0,0 +1,290 @@
+package jsonkit
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content codings decoded from requests and produced by Compress.
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// Defaults used by NewCodec.
+const (
+	DefaultMaxDecompressedBytes = 32 << 20 // 32 MiB
+	DefaultCompressMinSize      = 1024
+)
+
+// requestBody returns the request body limited to MaxBodyBytes on the wire,
+// decoding gzip and deflate Content-Encoding within MaxDecompressedBytes.
+func (c *Codec) requestBody(r *http.Request) (io.Reader, error) {
+	body := c.limitBody(r.Body)
+
+	var (
+		decoded io.Reader
+		err     error
+	)
+	switch encoding := contentEncoding(r); encoding {
+	case "", "identity":
+		return body, nil
+	case EncodingGzip, "x-gzip":
+		decoded, err = gzip.NewReader(body)
+	case EncodingDeflate:
+		decoded, err = newDeflateReader(body)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+	if err != nil {
+		return nil, wrapDecompressError(err)
+	}
+
+	decoded = &decompressErrorReader{r: decoded}
+	if c.MaxDecompressedBytes > 0 {
+		decoded = &bodyLimitReader{r: decoded, remaining: c.MaxDecompressedBytes}
+	}
+	return decoded, nil
+}
+
+// contentEncoding returns the normalized Content-Encoding of r.
+func contentEncoding(r *http.Request) string {
+	return strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+}
+
+// contentEncoded reports whether requestBody decodes the body of r.
+func contentEncoded(r *http.Request) bool {
+	encoding := contentEncoding(r)
+	return encoding != "" && encoding != "identity"
+}
+
+// newDeflateReader reads the zlib format HTTP calls deflate,
+// accepting the raw deflate streams some clients send instead.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	// A zlib header declares the deflate method and is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// decompressErrorReader reports corrupt compressed data as a DecodeError.
+type decompressErrorReader struct {
+	r io.Reader
+}
+
+func (d *decompressErrorReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = wrapDecompressError(err)
+	}
+	return n, err
+}
+
+func wrapDecompressError(err error) error {
+	var corrupt flate.CorruptInputError
+	switch {
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, gzip.ErrHeader),
+		errors.Is(err, gzip.ErrChecksum),
+		errors.Is(err, zlib.ErrHeader),
+		errors.Is(err, zlib.ErrChecksum),
+		errors.As(err, &corrupt):
+		return &DecodeError{Err: err}
+	default:
+		return err
+	}
+}
+
+// Compress compresses responses with the default codec, see Codec.Compress.
+func Compress(next http.Handler) http.Handler {
+	return defaultCodec.Compress(next)
+}
+
+// Compress is a middleware compressing responses with the encoding preferred by the request
+// Accept-Encoding header. Responses smaller than CompressMinSize are sent uncompressed,
+// as are responses that already have a Content-Encoding. Flushing a response,
+// as streaming writers do, starts compression regardless of the size written so far.
+func (c *Codec) Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: c.CompressMinSize}
+		defer func() { _ = cw.Close() }()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the supported content coding with the highest quality, preferring gzip.
+// The "*" wildcard only applies to codings that are not listed explicitly.
+func negotiateEncoding(accept []string) string {
+	qualities := map[string]float64{}
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "*" && coding != EncodingGzip && coding != EncodingDeflate {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				var err error
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			qualities[coding] = quality
+		}
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, coding := range []string{EncodingGzip, EncodingDeflate} {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it knows whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status    int
+	buf       []byte
+	committed bool
+	encoder   io.WriteCloser // nil when the response is sent uncompressed
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.committed || cw.status != 0 {
+		return
+	}
+	if status < http.StatusOK {
+		// informational responses precede the final one
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	// Bodiless responses are never compressed
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = cw.commit(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.committed {
+		if cw.Header().Get("Content-Encoding") != "" {
+			if err := cw.commit(false); err != nil {
+				return 0, err
+			}
+		} else {
+			cw.buf = append(cw.buf, p...)
+			if len(cw.buf) < cw.minSize {
+				return len(p), nil
+			}
+			return len(p), cw.commit(true)
+		}
+	}
+
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.committed {
+		_ = cw.commit(cw.Header().Get("Content-Encoding") == "")
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close sends a response still buffered and terminates the compressed stream.
+func (cw *compressWriter) Close() error {
+	if !cw.committed {
+		if err := cw.commit(len(cw.buf) > 0 && len(cw.buf) >= cw.minSize); err != nil {
+			return err
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// commit writes the header and the buffered body, compressed or not.
+func (cw *compressWriter) commit(compress bool) error {
+	cw.committed = true
+	if compress {
+		// net/http can't sniff the type of compressed bytes, sniff the plaintext instead.
+		// A Content-Type set to nil by the handler disables sniffing, as it does for net/http.
+		if _, ok := cw.Header()["Content-Type"]; !ok && len(cw.buf) > 0 {
+			cw.Header().Set("Content-Type", http.DetectContentType(cw.buf))
+		}
+		cw.Header().Set("Content-Encoding", cw.encoding)
+		cw.Header().Del("Content-Length")
+		if cw.encoding == EncodingGzip {
+			cw.encoder = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.encoder = zlib.NewWriter(cw.ResponseWriter)
+		}
+	}
+
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.encoder != nil {
+		_, err := cw.encoder.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
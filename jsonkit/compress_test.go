@@ -0,0 +1,261 @@
+package jsonkit_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+	"google.golang.org/protobuf/proto"
+)
+
+type CompressSuite struct {
+	suite.Suite
+}
+
+func (s *CompressSuite) compress(encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	default:
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		s.Require().Nil(err)
+		w = fw
+	}
+	_, err := w.Write(data)
+	s.Require().Nil(err)
+	s.Require().Nil(w.Close())
+	return buf.Bytes()
+}
+
+func (s *CompressSuite) newRequest(contentEncoding string, body []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Encoding", contentEncoding)
+	return req
+}
+
+func (s *CompressSuite) TestBindRequestBody_Decompress() {
+	body := []byte(`{"name": "John", "age": 30}`)
+	cases := []struct {
+		contentEncoding string
+		data            []byte
+	}{
+		{"gzip", s.compress("gzip", body)},
+		{"deflate", s.compress("zlib", body)},
+		{"deflate", s.compress("flate", body)},
+		{"identity", body},
+	}
+
+	for _, c := range cases {
+		var user pb.User
+		err := jsonkit.BindRequestBody(s.newRequest(c.contentEncoding, c.data), &user)
+
+		s.Nil(err, c.contentEncoding)
+		s.Equal("John", user.Name, c.contentEncoding)
+	}
+}
+
+func (s *CompressSuite) TestBindProtoRequest_Decompress() {
+	req := s.newRequest("gzip", s.compress("gzip", []byte(`{"name": "John"}`)))
+	msg := &pb.User{}
+
+	err := jsonkit.BindProtoRequest(req, msg)
+
+	s.Nil(err)
+	s.True(proto.Equal(&pb.User{Name: "John"}, msg))
+
+	// The restored body is decoded
+	s.Empty(req.Header.Get("Content-Encoding"))
+	body, err := io.ReadAll(req.Body)
+	s.Nil(err)
+	s.Equal(`{"name": "John"}`, string(body))
+}
+
+func (s *CompressSuite) TestBindRequestBody_DecompressionBomb() {
+	codec := jsonkit.NewCodec()
+	codec.MaxDecompressedBytes = 1024
+	bomb := s.compress("gzip", []byte(`{"name": "`+strings.Repeat("a", 1<<20)+`"}`))
+	s.Less(len(bomb), 4096)
+
+	err := codec.BindRequestBody(s.newRequest("gzip", bomb), &pb.User{})
+
+	s.ErrorIs(err, jsonkit.ErrBodyTooLarge)
+	s.Equal(http.StatusRequestEntityTooLarge, jsonkit.ErrorStatus(err))
+}
+
+func (s *CompressSuite) TestBindRequestBody_CorruptData() {
+	valid := s.compress("gzip", []byte(`{"name": "John"}`))
+	corrupt := append([]byte{}, valid...)
+	corrupt[len(corrupt)-5] ^= 0xff // breaks the checksum
+
+	cases := [][]byte{[]byte("not gzip"), valid[:len(valid)/2], corrupt}
+
+	for _, data := range cases {
+		err := jsonkit.BindRequestBody(s.newRequest("gzip", data), &pb.User{})
+
+		var decodeErr *jsonkit.DecodeError
+		s.True(errors.As(err, &decodeErr), err)
+	}
+}
+
+func (s *CompressSuite) TestBindRequestBody_UnsupportedEncoding() {
+	err := jsonkit.BindRequestBody(s.newRequest("br", []byte(`{}`)), &pb.User{})
+
+	s.ErrorIs(err, jsonkit.ErrUnsupportedEncoding)
+	s.Equal(http.StatusUnsupportedMediaType, jsonkit.ErrorStatus(err))
+}
+
+func (s *CompressSuite) serve(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func (s *CompressSuite) decompress(encoding string, data []byte) string {
+	var (
+		r   io.Reader
+		err error
+	)
+	if encoding == "gzip" {
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	} else {
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	}
+	s.Require().Nil(err)
+	decoded, err := io.ReadAll(r)
+	s.Require().Nil(err)
+	return string(decoded)
+}
+
+func (s *CompressSuite) TestCompress_Negotiation() {
+	large := map[string]string{"data": strings.Repeat("x", 2048)}
+	h := jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = jsonkit.JSONResponse(w, http.StatusCreated, large)
+	}))
+	expected, err := jsonkit.Marshal(large)
+	s.Nil(err)
+
+	cases := []struct {
+		acceptEncoding string
+		encoding       string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"*", "gzip"},
+		{"br", ""},
+		{"gzip;q=0", ""},
+		{"gzip;q=0, *", "deflate"},
+		{"*, gzip;q=0, deflate;q=0", ""},
+		{"*;q=0.5, deflate", "deflate"},
+	}
+
+	for _, c := range cases {
+		w := s.serve(h, c.acceptEncoding)
+
+		s.Equal(http.StatusCreated, w.Code, c.acceptEncoding)
+		s.Equal(c.encoding, w.Header().Get("Content-Encoding"), c.acceptEncoding)
+		s.Equal("Accept-Encoding", w.Header().Get("Vary"), c.acceptEncoding)
+		s.Equal(jsonkit.ContentTypeJSON, w.Header().Get("Content-Type"), c.acceptEncoding)
+		if c.encoding == "" {
+			s.Equal(string(expected), w.Body.String(), c.acceptEncoding)
+			continue
+		}
+		s.Less(w.Body.Len(), len(expected), c.acceptEncoding)
+		s.Equal(string(expected), s.decompress(c.encoding, w.Body.Bytes()), c.acceptEncoding)
+	}
+}
+
+func (s *CompressSuite) TestCompress_SniffContentType() {
+	page := "<!DOCTYPE html><html><body>" + strings.Repeat("x", 2048) + "</body></html>"
+	server := httptest.NewServer(jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, page)
+	})))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	s.Require().Nil(err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	// Setting Accept-Encoding keeps the transport from decompressing the response
+	resp, err := server.Client().Do(req)
+	s.Require().Nil(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	s.Require().Nil(err)
+
+	s.Equal("gzip", resp.Header.Get("Content-Encoding"))
+	s.Equal("text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	s.Equal(page, s.decompress("gzip", body))
+}
+
+func (s *CompressSuite) TestCompress_MinSize() {
+	h := jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = jsonkit.JSONResponse(w, http.StatusOK, map[string]string{"name": "John"})
+	}))
+
+	w := s.serve(h, "gzip")
+
+	s.Empty(w.Header().Get("Content-Encoding"))
+	s.JSONEq(`{"name": "John"}`, w.Body.String())
+}
+
+func (s *CompressSuite) TestCompress_AlreadyEncoded() {
+	payload := s.compress("gzip", []byte(strings.Repeat("x", 4096)))
+	h := jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(payload)
+	}))
+
+	w := s.serve(h, "gzip")
+
+	s.Equal(payload, w.Body.Bytes())
+}
+
+func (s *CompressSuite) TestCompress_Streaming() {
+	h := jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := jsonkit.NewNDJSONResponse(w, http.StatusOK)
+		_ = writer.Write(map[string]int{"n": 1})
+		_ = writer.Write(map[string]int{"n": 2})
+	}))
+
+	w := s.serve(h, "gzip")
+
+	s.Equal("gzip", w.Header().Get("Content-Encoding"))
+	s.True(w.Flushed)
+	s.Equal("{\"n\":1}\n{\"n\":2}\n", s.decompress("gzip", w.Body.Bytes()))
+}
+
+func (s *CompressSuite) TestCompress_NoContent() {
+	h := jsonkit.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := s.serve(h, "gzip")
+
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Empty(w.Header().Get("Content-Encoding"))
+	s.Zero(w.Body.Len())
+}
+
+func TestCompressSuite(t *testing.T) {
+	suite.Run(t, new(CompressSuite))
+}
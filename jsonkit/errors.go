@@ -0,0 +1,230 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrExtraData is returned when more JSON follows the decoded document.
+	ErrExtraData = errors.New("unexpected extra JSON data found")
+	// ErrNotArray is returned by the array decoders when the document is not a JSON array.
+	ErrNotArray = errors.New("expected a JSON array")
+	// ErrTooManyElements is returned when a JSON array exceeds ArrayLimits.MaxElements.
+	ErrTooManyElements = errors.New("JSON array has too many elements")
+	// ErrElementTooLarge is returned when a JSON array element exceeds ArrayLimits.MaxElementSize.
+	ErrElementTooLarge = errors.New("JSON array element is too large")
+	// ErrLineTooLarge is returned when a line of an NDJSON stream exceeds the maximum line size.
+	ErrLineTooLarge = errors.New("NDJSON line is too large")
+	// ErrStreamClosed is returned when writing to a closed SSEStream.
+	ErrStreamClosed = errors.New("SSE stream closed")
+	// ErrInvalidPointer is returned for malformed JSON Pointers.
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	// ErrPathNotFound is returned when a JSON Pointer doesn't resolve to a value.
+	ErrPathNotFound = errors.New("JSON pointer path not found")
+	// ErrInvalidPatch is returned for malformed JSON Patch operations.
+	ErrInvalidPatch = errors.New("invalid JSON patch")
+	// ErrTestFailed is returned when a JSON Patch test operation doesn't match.
+	ErrTestFailed = errors.New("JSON patch test failed")
+	// ErrUnsupportedMediaType is returned when a request body has an unexpected Content-Type.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrBodyTooLarge is returned when a request body exceeds Codec.MaxBodyBytes.
+	ErrBodyTooLarge = errors.New("request body too large")
+	// ErrUnsupportedEncoding is returned when a request body has a Content-Encoding other than gzip or deflate.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+	// ErrInvalidCursor is returned for page cursors that are malformed or fail signature verification.
+	ErrInvalidCursor = errors.New("invalid page cursor")
+	// ErrMaxDepth is returned when a document nests deeper than Codec.MaxDepth.
+	ErrMaxDepth = errors.New("document exceeds maximum nesting depth")
+	// ErrInvalidFieldMask is returned when a field mask path doesn't exist on the Protobuf message.
+	ErrInvalidFieldMask = errors.New("invalid field mask")
+)
+
+// DecodeError reports malformed input: invalid syntax, unknown fields, trailing data,
+// excessive nesting or a value that doesn't match the Go type or Protobuf message it is decoded into.
+// The message is the one of the wrapped error. Pointer locates the offending value when known.
+type DecodeError struct {
+	Pointer Pointer
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// requestError marks errors returned while binding a request, telling ErrorStatus
+// that a decode, validation or patch error was caused by the client rather than the server.
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// markRequestError marks err as caused by the request being bound, nil stays nil.
+func markRequestError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &requestError{err: err}
+}
+
+// wrapDecodeError wraps errors caused by the input in a DecodeError,
+// attaching the location reported by encoding/json when target, the value decoded into,
+// shows it unambiguously. Read errors are returned as is.
+//
+// encoding/json reports the location as dot separated object keys and array indexes. Keys containing
+// dots, or indexes left out by the legacy implementation, can't always be recovered, the Pointer is empty then.
+func wrapDecodeError(err error, target interface{}) error {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" || target == nil {
+			return &DecodeError{Err: err}
+		}
+		tokens := strings.Split(typeErr.Field, ".")
+		if fieldTokensEscaped() {
+			for i, token := range tokens {
+				if unescaped, err := unescapeToken(token); err == nil {
+					tokens[i] = unescaped
+				}
+			}
+		}
+		// Pointer holds raw tokens, String escapes ~ and / in each of them
+		pointer, paths := resolveFieldPath(reflect.TypeOf(target), tokens)
+		if paths != 1 {
+			pointer = nil
+		}
+		return &DecodeError{Pointer: pointer, Err: err}
+	case errors.As(err, &syntaxErr),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, ErrExtraData),
+		errors.Is(err, ErrMaxDepth),
+		strings.HasPrefix(err.Error(), "json: unknown field"):
+		return &DecodeError{Err: err}
+	default:
+		return err
+	}
+}
+
+// fieldTokensEscaped reports whether encoding/json escapes ~ and / in UnmarshalTypeError.Field
+// as JSON Pointer tokens do. The encoding/json v2 implementation does, the legacy one reports raw keys.
+var fieldTokensEscaped = sync.OnceValue(func() bool {
+	var probe struct {
+		Value int `json:"~"`
+	}
+	var typeErr *json.UnmarshalTypeError
+	err := json.Unmarshal([]byte(`{"~":""}`), &probe)
+	return errors.As(err, &typeErr) && typeErr.Field == "~0"
+})
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// resolveFieldPath splits the dot separated tokens of an UnmarshalTypeError.Field into the path
+// of a value of type t, returning it with the number of possible paths, counted up to 2.
+func resolveFieldPath(t reflect.Type, tokens []string) (Pointer, int) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if len(tokens) == 0 {
+		return Pointer{}, 1
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		// The layout of custom unmarshalers is unknown
+		return nil, 2
+	}
+
+	var (
+		found Pointer
+		paths int
+	)
+	follow := func(n int, next reflect.Type) {
+		if paths > 1 {
+			return
+		}
+		token := strings.Join(tokens[:n], ".")
+		rest, restPaths := resolveFieldPath(next, tokens[n:])
+		if restPaths > 0 {
+			found, paths = append(Pointer{token}, rest...), paths+restPaths
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, field := range jsonFields(t) {
+			n := strings.Count(field.name, ".") + 1
+			if n <= len(tokens) && strings.EqualFold(strings.Join(tokens[:n], "."), field.name) {
+				follow(n, field.typ)
+			}
+		}
+	case reflect.Map:
+		for n := 1; n <= len(tokens); n++ {
+			follow(n, t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(tokens[0]); err == nil {
+			follow(1, t.Elem())
+		}
+	case reflect.Interface:
+		if len(tokens) > 1 {
+			return nil, 2
+		}
+		return Pointer{tokens[0]}, 1
+	}
+	return found, paths
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields lists the fields of a struct by JSON name, promoting the fields of untagged embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: field.Type})
+	}
+	return fields
+}
@@ -0,0 +1,12 @@
+package jsonkit
+
+import "time"
+
+// SetTicker makes SSE heartbeats tick on ticks until the returned restore is called.
+func SetTicker(ticks <-chan time.Time) (restore func()) {
+	previous := newTicker
+	newTicker = func(time.Duration) (<-chan time.Time, func()) {
+		return ticks, func() {}
+	}
+	return func() { newTicker = previous }
+}
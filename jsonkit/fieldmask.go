@@ -0,0 +1,285 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// FieldMaskParam is the query parameter read by FieldMaskFromRequest, as in ?fields=name,email.
+const FieldMaskParam = "fields"
+
+// NewFieldMask builds a normalized FieldMask for m from dot separated paths.
+// Path segments may use either the proto field name or its JSON name,
+// the mask always holds proto names. Paths that don't exist on the message descriptor,
+// or that step into a repeated, map or scalar field, fail with ErrInvalidFieldMask.
+func NewFieldMask(m proto.Message, paths ...string) (*fieldmaskpb.FieldMask, error) {
+	desc := m.ProtoReflect().Descriptor()
+
+	mask := &fieldmaskpb.FieldMask{}
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		resolved, err := resolveMaskPath(desc, path)
+		if err != nil {
+			return nil, err
+		}
+		mask.Paths = append(mask.Paths, resolved)
+	}
+
+	mask.Normalize()
+	return mask, nil
+}
+
+// FieldMaskFromRequest reads the comma separated FieldMaskParam query parameter and validates it against m.
+// It returns a nil mask when the parameter is missing.
+func FieldMaskFromRequest(r *http.Request, m proto.Message) (*fieldmaskpb.FieldMask, error) {
+	values, ok := r.URL.Query()[FieldMaskParam]
+	if !ok {
+		return nil, nil
+	}
+
+	var paths []string
+	for _, value := range values {
+		paths = append(paths, strings.Split(value, ",")...)
+	}
+	return NewFieldMask(m, paths...)
+}
+
+// MarshalProtoWithMask encodes the fields of a Protobuf message selected by mask to JSON with the default codec.
+func MarshalProtoWithMask(v proto.Message, mask *fieldmaskpb.FieldMask) ([]byte, error) {
+	return defaultCodec.MarshalProtoWithMask(v, mask)
+}
+
+// ProtoJSONResponseWithMask writes the fields of a Protobuf message selected by mask as JSON to the response.
+func ProtoJSONResponseWithMask(w http.ResponseWriter, statusCode int, v proto.Message, mask *fieldmaskpb.FieldMask) error {
+	return defaultCodec.ProtoJSONResponseWithMask(w, statusCode, v, mask)
+}
+
+// ApplyProtoPatch merges a protojson document into dst, restricted to mask.
+// Fields in the mask are replaced by the patch value, or cleared when the patch leaves them unset,
+// every other field of dst is kept. An empty mask is inferred from the fields present in the patch.
+func ApplyProtoPatch(dst proto.Message, data []byte, mask *fieldmaskpb.FieldMask) error {
+	return defaultCodec.ApplyProtoPatch(dst, data, mask)
+}
+
+// BindProtoPatch merges the protojson request body into dst, see ApplyProtoPatch.
+func BindProtoPatch(r *http.Request, dst proto.Message, mask *fieldmaskpb.FieldMask) error {
+	return defaultCodec.BindProtoPatch(r, dst, mask)
+}
+
+// MarshalProtoWithMask encodes the fields of a Protobuf message selected by mask to JSON
+// using ProtoMarshalOptions. Selected fields are emitted even when unpopulated if EmitUnpopulated is set.
+// An empty mask selects every field.
+func (c *Codec) MarshalProtoWithMask(v proto.Message, mask *fieldmaskpb.FieldMask) ([]byte, error) {
+	if len(mask.GetPaths()) == 0 {
+		return c.MarshalProto(v)
+	}
+
+	mask, err := NewFieldMask(v, mask.GetPaths()...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.MarshalProto(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := documentCodec.UnMarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	doc = c.pruneDocument(doc, v.ProtoReflect().Descriptor(), newFieldMaskTree(mask.GetPaths()))
+
+	if c.ProtoMarshalOptions.Multiline {
+		indent := c.ProtoMarshalOptions.Indent
+		if indent == "" {
+			indent = "  "
+		}
+		return json.MarshalIndent(doc, "", indent)
+	}
+	return json.Marshal(doc)
+}
+
+// ProtoJSONResponseWithMask writes the fields of a Protobuf message selected by mask as JSON to the response.
+func (c *Codec) ProtoJSONResponseWithMask(w http.ResponseWriter, statusCode int, v proto.Message, mask *fieldmaskpb.FieldMask) error {
+	data, err := c.MarshalProtoWithMask(v, mask)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(statusCode)
+	_, err = w.Write(data)
+	return err
+}
+
+// ApplyProtoPatch merges a protojson document into dst restricted to mask, see ApplyProtoPatch.
+func (c *Codec) ApplyProtoPatch(dst proto.Message, data []byte, mask *fieldmaskpb.FieldMask) error {
+	src := dst.ProtoReflect().New().Interface()
+	if err := c.UnMarshalProto(data, src); err != nil {
+		return err
+	}
+
+	paths := mask.GetPaths()
+	if len(paths) == 0 {
+		var doc map[string]interface{}
+		if err := documentCodec.UnMarshal(data, &doc); err != nil {
+			return err
+		}
+		paths = patchPaths(doc, dst.ProtoReflect().Descriptor(), "")
+	}
+
+	mask, err := NewFieldMask(dst, paths...)
+	if err != nil {
+		return err
+	}
+	for _, path := range mask.GetPaths() {
+		mergeMaskPath(dst.ProtoReflect(), src.ProtoReflect(), strings.Split(path, "."))
+	}
+	return nil
+}
+
+// BindProtoPatch merges the protojson request body into dst, see ApplyProtoPatch.
+func (c *Codec) BindProtoPatch(r *http.Request, dst proto.Message, mask *fieldmaskpb.FieldMask) error {
+	data, err := c.readBody(r)
+	if err != nil {
+		return markRequestError(err)
+	}
+	return markRequestError(c.ApplyProtoPatch(dst, data, mask))
+}
+
+// resolveMaskPath checks a field mask path against desc and rewrites JSON names to proto names.
+func resolveMaskPath(desc protoreflect.MessageDescriptor, path string) (string, error) {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		if desc == nil {
+			return "", fmt.Errorf("%w: %q: %s has no subfields", ErrInvalidFieldMask, path, segments[i-1])
+		}
+
+		fields := desc.Fields()
+		fd := fields.ByName(protoreflect.Name(segment))
+		if fd == nil {
+			fd = fields.ByJSONName(segment)
+		}
+		if fd == nil {
+			return "", fmt.Errorf("%w: %q: no field %s in %s", ErrInvalidFieldMask, path, segment, desc.FullName())
+		}
+
+		segments[i] = string(fd.Name())
+		desc = nil
+		if fd.Message() != nil && fd.Cardinality() != protoreflect.Repeated {
+			desc = fd.Message()
+		}
+	}
+	return strings.Join(segments, "."), nil
+}
+
+// fieldMaskTree indexes normalized mask paths by segment, a nil subtree selects the whole field.
+type fieldMaskTree map[protoreflect.Name]fieldMaskTree
+
+func newFieldMaskTree(paths []string) fieldMaskTree {
+	tree := fieldMaskTree{}
+	for _, path := range paths {
+		node := tree
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			name := protoreflect.Name(segment)
+			if i == len(segments)-1 {
+				node[name] = nil
+				break
+			}
+			child, ok := node[name]
+			if ok && child == nil {
+				// the parent field is already selected as a whole
+				break
+			}
+			if !ok {
+				child = fieldMaskTree{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// pruneDocument keeps the members of a marshaled message that are selected by tree.
+func (c *Codec) pruneDocument(doc map[string]interface{}, desc protoreflect.MessageDescriptor, tree fieldMaskTree) map[string]interface{} {
+	pruned := make(map[string]interface{}, len(tree))
+	for name, subtree := range tree {
+		fd := desc.Fields().ByName(name)
+		key := fd.JSONName()
+		if c.ProtoMarshalOptions.UseProtoNames {
+			key = string(fd.Name())
+		}
+
+		value, ok := doc[key]
+		if !ok {
+			continue
+		}
+		// Well known types such as Timestamp marshal to scalars and are kept whole
+		if child, isObject := value.(map[string]interface{}); isObject && subtree != nil {
+			value = c.pruneDocument(child, fd.Message(), subtree)
+		}
+		pruned[key] = value
+	}
+	return pruned
+}
+
+// patchPaths lists the field paths set by a protojson patch document,
+// descending into nested messages so sibling fields of dst are kept.
+func patchPaths(doc map[string]interface{}, desc protoreflect.MessageDescriptor, prefix string) []string {
+	var paths []string
+	for key, value := range doc {
+		fd := desc.Fields().ByJSONName(key)
+		if fd == nil {
+			fd = desc.Fields().ByName(protoreflect.Name(key))
+		}
+		if fd == nil {
+			// Unknown fields were rejected or discarded by UnMarshalProto
+			continue
+		}
+
+		path := prefix + string(fd.Name())
+		if child, isObject := value.(map[string]interface{}); isObject && len(child) > 0 && hasMessageFields(fd) {
+			paths = append(paths, patchPaths(child, fd.Message(), path+".")...)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// hasMessageFields reports whether a field holds a single message whose JSON form is an object of its fields.
+func hasMessageFields(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil &&
+		fd.Cardinality() != protoreflect.Repeated &&
+		!strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf.")
+}
+
+// mergeMaskPath copies the field at path from src to dst, clearing it in dst when src leaves it unset.
+func mergeMaskPath(dst, src protoreflect.Message, path []string) {
+	fd := dst.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if len(path) == 1 {
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		} else {
+			dst.Clear(fd)
+		}
+		return
+	}
+
+	if !src.Has(fd) && !dst.Has(fd) {
+		return
+	}
+	mergeMaskPath(dst.Mutable(fd).Message(), src.Get(fd).Message(), path[1:])
+}
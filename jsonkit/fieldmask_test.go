@@ -0,0 +1,155 @@
+package jsonkit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+type FieldMaskSuite struct {
+	suite.Suite
+}
+
+func (s *FieldMaskSuite) TestNewFieldMask() {
+	mask, err := jsonkit.NewFieldMask(&typepb.Type{}, "sourceContext.fileName", "name", " ", "source_context")
+
+	s.Nil(err)
+	s.Equal([]string{"name", "source_context"}, mask.GetPaths())
+}
+
+func (s *FieldMaskSuite) TestNewFieldMask_Invalid() {
+	cases := []string{"missing", "name.length", "fields.name", "source_context.missing"}
+
+	for _, path := range cases {
+		_, err := jsonkit.NewFieldMask(&typepb.Type{}, path)
+
+		s.ErrorIs(err, jsonkit.ErrInvalidFieldMask, path)
+	}
+}
+
+func (s *FieldMaskSuite) TestFieldMaskFromRequest() {
+	req := httptest.NewRequest(http.MethodGet, "/?fields=name,age&fields=city", nil)
+
+	mask, err := jsonkit.FieldMaskFromRequest(req, &pb.User{})
+
+	s.Nil(err)
+	s.Equal([]string{"age", "city", "name"}, mask.GetPaths())
+
+	mask, err = jsonkit.FieldMaskFromRequest(httptest.NewRequest(http.MethodGet, "/", nil), &pb.User{})
+	s.Nil(err)
+	s.Nil(mask)
+
+	_, err = jsonkit.FieldMaskFromRequest(httptest.NewRequest(http.MethodGet, "/?fields=email", nil), &pb.User{})
+	s.ErrorIs(err, jsonkit.ErrInvalidFieldMask)
+}
+
+func (s *FieldMaskSuite) TestMarshalProtoWithMask() {
+	user := &pb.User{Name: "John", Age: 30, City: "New York"}
+
+	data, err := jsonkit.MarshalProtoWithMask(user, &fieldmaskpb.FieldMask{Paths: []string{"name", "age"}})
+	s.Nil(err)
+	s.JSONEq(`{"name":"John","age":30}`, string(data))
+
+	// Selected fields are emitted even when unpopulated
+	data, err = jsonkit.MarshalProtoWithMask(&pb.User{Name: "John"}, &fieldmaskpb.FieldMask{Paths: []string{"city"}})
+	s.Nil(err)
+	s.JSONEq(`{"city":""}`, string(data))
+
+	data, err = jsonkit.MarshalProtoWithMask(user, nil)
+	s.Nil(err)
+	s.JSONEq(`{"name":"John","age":30,"city":"New York"}`, string(data))
+
+	_, err = jsonkit.MarshalProtoWithMask(user, &fieldmaskpb.FieldMask{Paths: []string{"email"}})
+	s.ErrorIs(err, jsonkit.ErrInvalidFieldMask)
+}
+
+func (s *FieldMaskSuite) TestMarshalProtoWithMask_Nested() {
+	msg := &typepb.Type{
+		Name:          "Example",
+		Oneofs:        []string{"kind"},
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "example.proto"},
+	}
+
+	data, err := jsonkit.MarshalProtoWithMask(msg, &fieldmaskpb.FieldMask{Paths: []string{"source_context.file_name"}})
+
+	s.Nil(err)
+	s.JSONEq(`{"sourceContext":{"fileName":"example.proto"}}`, string(data))
+}
+
+func (s *FieldMaskSuite) TestProtoJSONResponseWithMask() {
+	req := httptest.NewRequest(http.MethodGet, "/?fields=name", nil)
+	w := httptest.NewRecorder()
+	user := &pb.User{Name: "John", Age: 30, City: "New York"}
+
+	mask, err := jsonkit.FieldMaskFromRequest(req, user)
+	s.Nil(err)
+	err = jsonkit.ProtoJSONResponseWithMask(w, http.StatusOK, user, mask)
+
+	s.Nil(err)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(jsonkit.ContentTypeJSON, w.Header().Get("Content-Type"))
+	s.JSONEq(`{"name":"John"}`, w.Body.String())
+}
+
+func (s *FieldMaskSuite) TestApplyProtoPatch_Mask() {
+	user := &pb.User{Name: "John", Age: 30, City: "New York"}
+
+	// city is in the mask but missing from the patch, so it is cleared;
+	// name is in the patch but not in the mask, so it is kept
+	err := jsonkit.ApplyProtoPatch(user, []byte(`{"name": "Jane", "age": 31}`), &fieldmaskpb.FieldMask{Paths: []string{"age", "city"}})
+
+	s.Nil(err)
+	s.True(proto.Equal(&pb.User{Name: "John", Age: 31}, user))
+}
+
+func (s *FieldMaskSuite) TestApplyProtoPatch_InferredMask() {
+	msg := &typepb.Type{
+		Name:          "Example",
+		Oneofs:        []string{"kind"},
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "example.proto"},
+	}
+
+	err := jsonkit.ApplyProtoPatch(msg, []byte(`{"oneofs": [], "sourceContext": {"fileName": "other.proto"}}`), nil)
+
+	s.Nil(err)
+	s.True(proto.Equal(&typepb.Type{
+		Name:          "Example",
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "other.proto"},
+	}, msg))
+}
+
+func (s *FieldMaskSuite) TestApplyProtoPatch_Errors() {
+	user := &pb.User{Name: "John"}
+
+	err := jsonkit.ApplyProtoPatch(user, []byte(`{"name": 1}`), nil)
+	var decodeErr *jsonkit.DecodeError
+	s.ErrorAs(err, &decodeErr)
+
+	err = jsonkit.ApplyProtoPatch(user, []byte(`{"name": "Jane"}`), &fieldmaskpb.FieldMask{Paths: []string{"email"}})
+	s.ErrorIs(err, jsonkit.ErrInvalidFieldMask)
+
+	s.True(proto.Equal(&pb.User{Name: "John"}, user))
+}
+
+func (s *FieldMaskSuite) TestBindProtoPatch() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"city": "Paris"}`))
+	user := &pb.User{Name: "John", Age: 30, City: "New York"}
+
+	err := jsonkit.BindProtoPatch(req, user, nil)
+
+	s.Nil(err)
+	s.True(proto.Equal(&pb.User{Name: "John", Age: 30, City: "Paris"}, user))
+}
+
+func TestFieldMaskSuite(t *testing.T) {
+	suite.Run(t, new(FieldMaskSuite))
+}
@@ -0,0 +1,231 @@
+package jsonkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// StatusCoder is implemented by errors that choose the HTTP status of their error response.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// HTTPError is an error carrying the HTTP status and the message sent to the client.
+// The wrapped Err is kept for logging and errors.Is, it is never sent to the client.
+type HTTPError struct {
+	Status  int
+	Message string // message sent to the client, the status text when empty
+	Err     error
+}
+
+// NewHTTPError creates an HTTPError with a client facing message.
+func NewHTTPError(status int, message string) *HTTPError {
+	return &HTTPError{Status: status, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	message := e.clientMessage()
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func (e *HTTPError) StatusCode() int {
+	return e.Status
+}
+
+func (e *HTTPError) clientMessage() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return http.StatusText(e.Status)
+}
+
+// ErrorResponse is the JSON body written by WriteError.
+type ErrorResponse struct {
+	Error   string   `json:"error"`
+	Pointer string   `json:"pointer,omitempty"` // JSON Pointer to the offending value of a decode error
+	Details []string `json:"details,omitempty"` // individual schema validation failures
+}
+
+// ErrorStatus maps an error to the HTTP status of its response.
+// Errors implementing StatusCoder choose their own status, jsonkit errors map to 4xx statuses
+// and any other error is a 500 Internal Server Error. Decode, validation and patch errors
+// are 400 only when returned by the bind helpers or Handler: the same errors raised by
+// the server decoding its own data, e.g. with UnMarshal, are 500.
+func ErrorStatus(err error) int {
+	var (
+		statusCoder    StatusCoder
+		requestErr     *requestError
+		decodeErr      *DecodeError
+		validationErrs ValidationErrors
+		patchErr       *PatchError
+	)
+	switch {
+	case errors.As(err, &statusCoder):
+		return statusCoder.StatusCode()
+	case errors.Is(err, ErrBodyTooLarge), errors.Is(err, ErrTooManyElements), errors.Is(err, ErrElementTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType), errors.Is(err, ErrUnsupportedEncoding):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrTestFailed):
+		return http.StatusConflict
+	case errors.As(err, &requestErr) && (errors.As(err, &decodeErr) ||
+		errors.As(err, &validationErrs) ||
+		errors.As(err, &patchErr)),
+		errors.Is(err, ErrInvalidFieldMask),
+		errors.Is(err, ErrInvalidCursor):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteError writes err as an ErrorResponse with the status chosen by ErrorStatus.
+func WriteError(w http.ResponseWriter, err error) error {
+	return defaultCodec.WriteError(w, err)
+}
+
+// WriteError writes err as an ErrorResponse, see WriteError.
+// The messages of server errors, including StatusCoder errors with a 5xx status,
+// are replaced by the status text so internal details don't leak to clients.
+// HTTPError always sends its client facing message. Pointers and validation details
+// are only sent with 4xx responses.
+func (c *Codec) WriteError(w http.ResponseWriter, err error) error {
+	status := ErrorStatus(err)
+	response := ErrorResponse{Error: err.Error()}
+
+	var (
+		httpErr        *HTTPError
+		decodeErr      *DecodeError
+		validationErrs ValidationErrors
+	)
+	switch {
+	case errors.As(err, &httpErr):
+		response.Error = httpErr.clientMessage()
+	case status >= http.StatusInternalServerError:
+		response.Error = http.StatusText(status)
+	}
+	if status < http.StatusInternalServerError {
+		if errors.As(err, &decodeErr) && len(decodeErr.Pointer) > 0 {
+			response.Pointer = decodeErr.Pointer.String()
+		}
+		if errors.As(err, &validationErrs) {
+			for _, validationErr := range validationErrs {
+				response.Details = append(response.Details, validationErr.Error())
+			}
+		}
+	}
+
+	return c.JSONResponse(w, status, response)
+}
+
+// Handler adapts a typed function to an http.Handler. The JSON request body is bound into Req,
+// the function result is written as JSON and errors are written with WriteError.
+// Requests without a body call the function with the zero Req.
+// When Req is a struct with parameter tags, or a pointer to one, BindParams fills them after the body;
+// a pointer Req is allocated for them when the request has no body.
+type Handler[Req, Resp any] struct {
+	Fn         func(ctx context.Context, req Req) (Resp, error)
+	Codec      *Codec // codec used to bind and write, the default codec when nil
+	StatusCode int    // status of successful responses, 200 when zero; 204 writes no body
+}
+
+// NewHandler creates a Handler calling fn with the default codec.
+func NewHandler[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) *Handler[Req, Resp] {
+	return &Handler[Req, Resp]{Fn: fn}
+}
+
+func (h *Handler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	codec := codecOrDefault(h.Codec)
+
+	var req Req
+	if hasBody(r) && formMediaType(r) == "" {
+		if err := codec.BindRequestBody(r, &req); err != nil {
+			_ = codec.WriteError(w, err)
+			return
+		}
+	}
+	if err := codec.bindTaggedParams(r, &req); err != nil {
+		_ = codec.WriteError(w, err)
+		return
+	}
+
+	resp, err := h.Fn(r.Context(), req)
+	if err != nil {
+		_ = codec.WriteError(w, err)
+		return
+	}
+
+	if h.StatusCode == http.StatusNoContent {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	_ = codec.JSONResponse(w, statusOrOK(h.StatusCode), resp)
+}
+
+// ProtoHandler is the Protobuf flavour of Handler, binding with BindProtoRequestBody
+// and writing with ProtoJSONResponse. Requests without a body call the function with an empty message.
+type ProtoHandler[Req, Resp proto.Message] struct {
+	Fn         func(ctx context.Context, req Req) (Resp, error)
+	Codec      *Codec // codec used to bind and write, the default codec when nil
+	StatusCode int    // status of successful responses, 200 when zero; 204 writes no body
+}
+
+// NewProtoHandler creates a ProtoHandler calling fn with the default codec.
+func NewProtoHandler[Req, Resp proto.Message](fn func(ctx context.Context, req Req) (Resp, error)) *ProtoHandler[Req, Resp] {
+	return &ProtoHandler[Req, Resp]{Fn: fn}
+}
+
+func (h *ProtoHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	codec := codecOrDefault(h.Codec)
+
+	// ProtoReflect works on nil messages, so the zero Req can create a new one
+	var zero Req
+	req := zero.ProtoReflect().New().Interface().(Req)
+	if hasBody(r) {
+		if err := codec.BindProtoRequestBody(r, req); err != nil {
+			_ = codec.WriteError(w, err)
+			return
+		}
+	}
+
+	resp, err := h.Fn(r.Context(), req)
+	if err != nil {
+		_ = codec.WriteError(w, err)
+		return
+	}
+
+	if h.StatusCode == http.StatusNoContent {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	_ = codec.ProtoJSONResponse(w, statusOrOK(h.StatusCode), resp)
+}
+
+func codecOrDefault(c *Codec) *Codec {
+	if c == nil {
+		return defaultCodec
+	}
+	return c
+}
+
+func statusOrOK(status int) int {
+	if status == 0 {
+		return http.StatusOK
+	}
+	return status
+}
+
+// hasBody reports whether the request carries a body, servers use http.NoBody for empty ones.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody
+}
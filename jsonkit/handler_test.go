@@ -0,0 +1,227 @@
+package jsonkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "short and stout" }
+func (teapotError) StatusCode() int { return http.StatusTeapot }
+
+type unavailableError struct{}
+
+func (unavailableError) Error() string   { return "dial tcp 10.0.0.7:5432: connection refused" }
+func (unavailableError) StatusCode() int { return http.StatusServiceUnavailable }
+
+type HandlerSuite struct {
+	suite.Suite
+}
+
+func (s *HandlerSuite) greet(_ context.Context, req greetRequest) (greetResponse, error) {
+	switch req.Name {
+	case "":
+		return greetResponse{}, jsonkit.NewHTTPError(http.StatusUnprocessableEntity, "name is required")
+	case "teapot":
+		return greetResponse{}, fmt.Errorf("brewing: %w", teapotError{})
+	case "db":
+		return greetResponse{}, errors.New("connection refused to 10.0.0.1")
+	case "stored":
+		var row struct{ A int }
+		if err := jsonkit.UnMarshal([]byte(`{"A": "x"}`), &row); err != nil {
+			return greetResponse{}, fmt.Errorf("loading row: %w", err)
+		}
+	}
+	return greetResponse{Greeting: "Hello " + req.Name}, nil
+}
+
+func (s *HandlerSuite) serve(h http.Handler, method, body string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, "/", nil)
+	} else {
+		req = httptest.NewRequest(method, "/", strings.NewReader(body))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func (s *HandlerSuite) TestHandler_Success() {
+	h := jsonkit.NewHandler(s.greet)
+	h.StatusCode = http.StatusCreated
+
+	w := s.serve(h, http.MethodPost, `{"name": "John"}`)
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal(jsonkit.ContentTypeJSON, w.Header().Get("Content-Type"))
+	s.JSONEq(`{"greeting": "Hello John"}`, w.Body.String())
+}
+
+func (s *HandlerSuite) TestHandler_Errors() {
+	h := jsonkit.NewHandler(s.greet)
+
+	cases := []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{``, http.StatusUnprocessableEntity, `{"error": "name is required"}`},
+		{`{"name": "teapot"}`, http.StatusTeapot, `{"error": "brewing: short and stout"}`},
+		{`{"name": "db"}`, http.StatusInternalServerError, `{"error": "Internal Server Error"}`},
+		{`{"name": "stored"}`, http.StatusInternalServerError, `{"error": "Internal Server Error"}`},
+		{`{"name": 1}`, http.StatusBadRequest, ``},
+		{`{"name": "John", "age": 30}`, http.StatusBadRequest, ``},
+	}
+
+	for _, c := range cases {
+		w := s.serve(h, http.MethodPost, c.body)
+
+		s.Equal(c.status, w.Code, c.body)
+		if c.status == http.StatusBadRequest {
+			// decode error messages depend on the encoding/json version
+			var resp jsonkit.ErrorResponse
+			s.Nil(jsonkit.UnMarshal(w.Body.Bytes(), &resp))
+			s.NotEmpty(resp.Error)
+			continue
+		}
+		s.JSONEq(c.expected, w.Body.String(), c.body)
+	}
+}
+
+func (s *HandlerSuite) TestHandler_DecodeErrorPointer() {
+	w := s.serve(jsonkit.NewHandler(s.greet), http.MethodPost, `{"name": 1}`)
+
+	var resp jsonkit.ErrorResponse
+	s.Nil(jsonkit.UnMarshal(w.Body.Bytes(), &resp))
+	s.Equal("/name", resp.Pointer)
+}
+
+func (s *HandlerSuite) TestHandler_Codec() {
+	h := jsonkit.NewHandler(s.greet)
+	h.Codec = jsonkit.NewCodec()
+	h.Codec.MaxBodyBytes = 8
+
+	w := s.serve(h, http.MethodPost, `{"name": "John"}`)
+
+	s.Equal(http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func (s *HandlerSuite) TestHandler_NoContent() {
+	h := &jsonkit.Handler[greetRequest, struct{}]{
+		Fn:         func(context.Context, greetRequest) (struct{}, error) { return struct{}{}, nil },
+		StatusCode: http.StatusNoContent,
+	}
+
+	w := s.serve(h, http.MethodDelete, "")
+
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Empty(w.Body.String())
+}
+
+func (s *HandlerSuite) TestProtoHandler() {
+	h := jsonkit.NewProtoHandler(func(_ context.Context, req *pb.User) (*pb.User, error) {
+		if req.Name == "" {
+			return nil, jsonkit.NewHTTPError(http.StatusBadRequest, "name is required")
+		}
+		req.City = "Paris"
+		return req, nil
+	})
+
+	w := s.serve(h, http.MethodPost, `{"name": "John", "age": 30}`)
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"name": "John", "age": 30, "city": "Paris"}`, w.Body.String())
+
+	w = s.serve(h, http.MethodPost, "")
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.JSONEq(`{"error": "name is required"}`, w.Body.String())
+
+	w = s.serve(h, http.MethodPost, `{"name": "John", "extra": 1}`)
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *HandlerSuite) TestErrorStatus() {
+	bindErr := jsonkit.BindRequestBody(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{} {}`)), &greetRequest{})
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{jsonkit.ErrBodyTooLarge, http.StatusRequestEntityTooLarge},
+		{fmt.Errorf("%w: text/xml", jsonkit.ErrUnsupportedMediaType), http.StatusUnsupportedMediaType},
+		{bindErr, http.StatusBadRequest},
+		{fmt.Errorf("binding: %w", bindErr), http.StatusBadRequest},
+		// Decode errors not raised while binding the request are the server's own
+		{&jsonkit.DecodeError{Err: jsonkit.ErrExtraData}, http.StatusInternalServerError},
+		{jsonkit.ValidationErrors{{Message: "bad"}}, http.StatusInternalServerError},
+		{&jsonkit.PatchError{Err: jsonkit.ErrInvalidPatch}, http.StatusInternalServerError},
+		{&jsonkit.PatchError{Err: jsonkit.ErrTestFailed}, http.StatusConflict},
+		{&jsonkit.HTTPError{Status: http.StatusNotFound, Err: errors.New("no rows")}, http.StatusNotFound},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		s.Equal(c.status, jsonkit.ErrorStatus(c.err), c.err.Error())
+	}
+}
+
+func (s *HandlerSuite) TestWriteError_ValidationDetails() {
+	codec := jsonkit.NewCodec()
+	codec.ValidateSchema = true
+	var v struct {
+		Age int `json:"age" jsonschema:"minimum=0"`
+	}
+	bindErr := codec.BindRequestBody(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age": -1}`)), &v)
+	w := httptest.NewRecorder()
+
+	err := codec.WriteError(w, bindErr)
+
+	s.Nil(err)
+	s.Equal(http.StatusBadRequest, w.Code)
+	var resp jsonkit.ErrorResponse
+	s.Nil(jsonkit.UnMarshal(w.Body.Bytes(), &resp))
+	s.Len(resp.Details, 1)
+	s.True(strings.HasPrefix(resp.Details[0], "/age: "), resp.Details[0])
+}
+
+func (s *HandlerSuite) TestWriteError_ServerDecodeError() {
+	w := httptest.NewRecorder()
+
+	err := jsonkit.WriteError(w, jsonkit.ValidationErrors{{Pointer: jsonkit.Pointer{"age"}, Keyword: "minimum", Message: "must be >= 0"}})
+
+	s.Nil(err)
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.JSONEq(`{"error": "Internal Server Error"}`, w.Body.String())
+}
+
+func (s *HandlerSuite) TestWriteError_StatusCoderServerError() {
+	w := httptest.NewRecorder()
+
+	err := jsonkit.WriteError(w, fmt.Errorf("loading user: %w", unavailableError{}))
+
+	s.Nil(err)
+	s.Equal(http.StatusServiceUnavailable, w.Code)
+	s.JSONEq(`{"error": "Service Unavailable"}`, w.Body.String())
+}
+
+func TestHandlerSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
@@ -125,6 +125,14 @@ func (s *JsonSuite) TestBindRequestBody_InvalidJson_Error() {
 	s.NotNil(err)
 }
 
+func (s *JsonSuite) TestBindRequestBody_TrailingDocument_ExtraData() {
+	jsonStr := `{"name": "John"} {"name": "Jane"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonStr))
+	var user pb.User
+	err := jsonkit.BindRequestBody(req, &user)
+	s.ErrorIs(err, jsonkit.ErrExtraData)
+}
+
 func (s *JsonSuite) TestJSONResponse_Success() {
 	w := httptest.NewRecorder()
 	user := &pb.User{Name: "John", Age: 30, City: "New York"}
@@ -0,0 +1,138 @@
+package jsonkit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// ContentTypeNDJSON is the media type used for newline delimited JSON streams.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// LineError reports a failure to decode a single line of an NDJSON stream.
+type LineError struct {
+	Line int // 1-based line number in the stream
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ReadNDJSON decodes a newline delimited JSON stream, yielding one T per line.
+// Every line is decoded with UnMarshal, so unknown fields and trailing data are rejected.
+// A line that fails to decode yields a *LineError and iteration continues with the next line;
+// a read error from r, or a line longer than maxLineBytes (0 for no limit) is yielded once
+// and ends the iteration. Blank lines are skipped.
+func ReadNDJSON[T any](r io.Reader, maxLineBytes int64) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		reader := bufio.NewReader(r)
+		line := 0
+
+		for {
+			data, err := readLine(reader, maxLineBytes)
+			if len(data) > 0 || errors.Is(err, ErrLineTooLarge) {
+				line++
+			}
+			if errors.Is(err, ErrLineTooLarge) {
+				var zero T
+				yield(zero, &LineError{Line: line, Err: err})
+				return
+			}
+
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				var v T
+				if decodeErr := UnMarshal(data, &v); decodeErr != nil {
+					var zero T
+					if !yield(zero, &LineError{Line: line, Err: decodeErr}) {
+						return
+					}
+				} else if !yield(v, nil) {
+					return
+				}
+			}
+
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// readLine reads up to and including the next newline,
+// failing with ErrLineTooLarge as soon as the line grows past max bytes.
+func readLine(reader *bufio.Reader, max int64) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if max > 0 && int64(len(bytes.TrimRight(line, "\r\n"))) > max {
+			return nil, ErrLineTooLarge
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+	}
+}
+
+// NDJSONWriter writes values as newline delimited JSON,
+// flushing after every record when the underlying writer supports it.
+type NDJSONWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+// NewNDJSONWriter creates a NDJSONWriter on top of w.
+// If w implements http.Flusher, every record is flushed as soon as it is written.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	flusher, _ := w.(http.Flusher)
+	return &NDJSONWriter{w: w, flusher: flusher}
+}
+
+// NewNDJSONResponse sets the NDJSON content type, writes the status code
+// and returns a NDJSONWriter streaming records to the response.
+func NewNDJSONResponse(w http.ResponseWriter, statusCode int) *NDJSONWriter {
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	w.WriteHeader(statusCode)
+	return NewNDJSONWriter(w)
+}
+
+// Write marshals v and writes it as a single line.
+func (w *NDJSONWriter) Write(v interface{}) error {
+	// Marshal already terminates the document with a newline
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.w.Write(data); err != nil {
+		return err
+	}
+
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// WriteNDJSON writes every value of seq to w, stopping at the first error.
+func WriteNDJSON[T any](w *NDJSONWriter, seq iter.Seq[T]) error {
+	for v := range seq {
+		if err := w.Write(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package jsonkit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+)
+
+type NDJSONSuite struct {
+	suite.Suite
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_Success() {
+	stream := `{"name": "John", "age": 30}` + "\n" +
+		"\n" +
+		`{"name": "Jane", "age": 25}` + "\r\n"
+
+	var names []string
+	for user, err := range jsonkit.ReadNDJSON[*pb.User](strings.NewReader(stream), 0) {
+		s.Nil(err)
+		names = append(names, user.Name)
+	}
+
+	s.Equal([]string{"John", "Jane"}, names)
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_NoTrailingNewline() {
+	stream := `{"name": "John"}`
+
+	count := 0
+	for _, err := range jsonkit.ReadNDJSON[*pb.User](strings.NewReader(stream), 0) {
+		s.Nil(err)
+		count++
+	}
+
+	s.Equal(1, count)
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_LineError() {
+	stream := `{"name": "John"}` + "\n" +
+		`{"name": "Jane", "extra": "field"}` + "\n" +
+		`{"name": "Bob"}` + "\n"
+
+	var lineErr *jsonkit.LineError
+	var names []string
+	for user, err := range jsonkit.ReadNDJSON[*pb.User](strings.NewReader(stream), 0) {
+		if err != nil {
+			s.True(errors.As(err, &lineErr))
+			continue
+		}
+		names = append(names, user.Name)
+	}
+
+	s.Equal([]string{"John", "Bob"}, names)
+	s.Equal(2, lineErr.Line)
+	s.Contains(lineErr.Error(), "ndjson line 2")
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_LineTooLarge() {
+	stream := `{"name": "John"}` + "\r\n" +
+		`{"name": "` + strings.Repeat("a", 8192) + `"}` + "\n" +
+		`{"name": "Bob"}` + "\n"
+
+	var names []string
+	var errs []error
+	for user, err := range jsonkit.ReadNDJSON[*pb.User](strings.NewReader(stream), 16) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		names = append(names, user.Name)
+	}
+
+	s.Equal([]string{"John"}, names)
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], jsonkit.ErrLineTooLarge)
+	var lineErr *jsonkit.LineError
+	s.True(errors.As(errs[0], &lineErr))
+	s.Equal(2, lineErr.Line)
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_ReadError() {
+	readErr := errors.New("read failed")
+	r := iotest.ErrReader(readErr)
+
+	var errs []error
+	for _, err := range jsonkit.ReadNDJSON[*pb.User](r, 0) {
+		errs = append(errs, err)
+	}
+
+	s.Len(errs, 1)
+	s.ErrorIs(errs[0], readErr)
+}
+
+func (s *NDJSONSuite) TestReadNDJSON_Break() {
+	stream := strings.Repeat(`{"name": "John"}`+"\n", 10)
+
+	count := 0
+	for range jsonkit.ReadNDJSON[*pb.User](strings.NewReader(stream), 0) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+
+	s.Equal(3, count)
+}
+
+func (s *NDJSONSuite) TestNDJSONResponse_Success() {
+	w := httptest.NewRecorder()
+
+	writer := jsonkit.NewNDJSONResponse(w, http.StatusOK)
+	err := jsonkit.WriteNDJSON(writer, slices.Values([]*pb.User{{Name: "John"}, {Name: "Jane"}}))
+
+	s.Nil(err)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(jsonkit.ContentTypeNDJSON, w.Header().Get("Content-Type"))
+	s.True(w.Flushed)
+	s.Equal(`{"name":"John"}`+"\n"+`{"name":"Jane"}`+"\n", w.Body.String())
+}
+
+func (s *NDJSONSuite) TestNDJSONWriter_InvalidData() {
+	var sb strings.Builder
+	writer := jsonkit.NewNDJSONWriter(&sb)
+
+	err := writer.Write(make(chan int))
+
+	s.NotNil(err)
+	s.Empty(sb.String())
+}
+
+func TestNDJSONSuite(t *testing.T) {
+	suite.Run(t, new(NDJSONSuite))
+}
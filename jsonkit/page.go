@@ -0,0 +1,131 @@
+package jsonkit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CursorParam is the query parameter carrying page cursors in Link headers.
+const CursorParam = "cursor"
+
+// Page is the envelope of list responses.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // empty on the last page
+	PrevCursor string `json:"prev_cursor,omitempty"` // empty on the first page
+	Total      *int64 `json:"total,omitempty"`       // nil when counting is too expensive
+}
+
+// PageResponse writes a page as JSON, with a Link header pointing to the request URL
+// with CursorParam set to the next and previous cursors. Nil items are written as an empty array.
+func PageResponse[T any](w http.ResponseWriter, r *http.Request, statusCode int, page Page[T]) error {
+	if links := PageLinks(r.URL, page.NextCursor, page.PrevCursor); links != "" {
+		w.Header().Set("Link", links)
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return JSONResponse(w, statusCode, page)
+}
+
+// PageLinks formats an RFC 8288 Link header value with "next" and "prev" relations
+// to u with CursorParam replaced. Empty cursors are left out, so is the whole value when both are.
+func PageLinks(u *url.URL, next, prev string) string {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{{"next", next}, {"prev", prev}} {
+		if link.cursor == "" {
+			continue
+		}
+		target := *u
+		query := target.Query()
+		query.Set(CursorParam, link.cursor)
+		target.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+	return strings.Join(links, ", ")
+}
+
+// CursorCodec encodes page positions into opaque cursors: base64 encoded JSON,
+// followed by an HMAC-SHA256 signature when Key is set so clients cannot forge positions.
+type CursorCodec struct {
+	Key []byte
+}
+
+var cursorEncoding = base64.RawURLEncoding
+
+// NewCursorCodec creates a CursorCodec signing cursors with key, an empty key leaves them unsigned.
+func NewCursorCodec(key []byte) *CursorCodec {
+	return &CursorCodec{Key: key}
+}
+
+// EncodeCursor encodes v into an unsigned cursor.
+func EncodeCursor(v interface{}) (string, error) {
+	return (&CursorCodec{}).Encode(v)
+}
+
+// DecodeCursor decodes an unsigned cursor into v.
+func DecodeCursor(cursor string, v interface{}) error {
+	return (&CursorCodec{}).Decode(cursor, v)
+}
+
+// Encode marshals v into a cursor.
+func (c *CursorCodec) Encode(v interface{}) (string, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	cursor := cursorEncoding.EncodeToString(bytes.TrimSuffix(data, []byte("\n")))
+	if len(c.Key) == 0 {
+		return cursor, nil
+	}
+	return cursor + "." + cursorEncoding.EncodeToString(c.sign(cursor)), nil
+}
+
+// Decode verifies a cursor and unmarshals it into v.
+// Malformed, tampered or unsigned cursors fail with ErrInvalidCursor.
+func (c *CursorCodec) Decode(cursor string, v interface{}) error {
+	payload, signature, signed := strings.Cut(cursor, ".")
+	if len(c.Key) > 0 {
+		mac, err := cursorEncoding.DecodeString(signature)
+		if !signed || err != nil || !hmac.Equal(mac, c.sign(payload)) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+		}
+	} else if signed {
+		return fmt.Errorf("%w: unexpected signature", ErrInvalidCursor)
+	}
+
+	data, err := cursorEncoding.DecodeString(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := UnMarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return nil
+}
+
+// DecodeRequest decodes the CursorParam query parameter into v.
+// It reports false without error when the request has no cursor, for the first page.
+func (c *CursorCodec) DecodeRequest(r *http.Request, v interface{}) (bool, error) {
+	cursor := r.URL.Query().Get(CursorParam)
+	if cursor == "" {
+		return false, nil
+	}
+	if err := c.Decode(cursor, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.Key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,118 @@
+package jsonkit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+type pageCursor struct {
+	AfterID int64  `json:"after_id"`
+	Sort    string `json:"sort"`
+}
+
+type PageSuite struct {
+	suite.Suite
+}
+
+func (s *PageSuite) TestPageResponse() {
+	req := httptest.NewRequest(http.MethodGet, "/users?limit=2&cursor=old", nil)
+	w := httptest.NewRecorder()
+	total := int64(5)
+
+	err := jsonkit.PageResponse(w, req, http.StatusOK, jsonkit.Page[string]{
+		Items:      []string{"a", "b"},
+		NextCursor: "n1",
+		PrevCursor: "p1",
+		Total:      &total,
+	})
+
+	s.Nil(err)
+	s.JSONEq(`{"items": ["a", "b"], "next_cursor": "n1", "prev_cursor": "p1", "total": 5}`, w.Body.String())
+	s.Equal(`</users?cursor=n1&limit=2>; rel="next", </users?cursor=p1&limit=2>; rel="prev"`, w.Header().Get("Link"))
+}
+
+func (s *PageSuite) TestPageResponse_Empty() {
+	w := httptest.NewRecorder()
+
+	err := jsonkit.PageResponse(w, httptest.NewRequest(http.MethodGet, "/users", nil), http.StatusOK, jsonkit.Page[int]{})
+
+	s.Nil(err)
+	s.JSONEq(`{"items": []}`, w.Body.String())
+	s.Empty(w.Header().Values("Link"))
+}
+
+func (s *PageSuite) TestPageLinks_AbsoluteURL() {
+	u, err := url.Parse("https://api.example.com/v1/orders?status=open")
+	s.Nil(err)
+
+	links := jsonkit.PageLinks(u, "a+b/c", "")
+
+	s.Equal(`<https://api.example.com/v1/orders?cursor=a%2Bb%2Fc&status=open>; rel="next"`, links)
+}
+
+func (s *PageSuite) TestCursor_Unsigned() {
+	cursor, err := jsonkit.EncodeCursor(pageCursor{AfterID: 42, Sort: "name"})
+	s.Nil(err)
+	s.NotContains(cursor, "=")
+
+	var decoded pageCursor
+	s.Nil(jsonkit.DecodeCursor(cursor, &decoded))
+	s.Equal(pageCursor{AfterID: 42, Sort: "name"}, decoded)
+
+	s.ErrorIs(jsonkit.DecodeCursor("not base64!", &decoded), jsonkit.ErrInvalidCursor)
+	s.ErrorIs(jsonkit.DecodeCursor(cursor+".c2ln", &decoded), jsonkit.ErrInvalidCursor)
+}
+
+func (s *PageSuite) TestCursor_Signed() {
+	codec := jsonkit.NewCursorCodec([]byte("secret"))
+
+	cursor, err := codec.Encode(pageCursor{AfterID: 42})
+	s.Nil(err)
+
+	var decoded pageCursor
+	s.Nil(codec.Decode(cursor, &decoded))
+	s.Equal(int64(42), decoded.AfterID)
+
+	// A cursor re-encoded by the client fails verification
+	forged, err := jsonkit.EncodeCursor(pageCursor{AfterID: 1})
+	s.Nil(err)
+	cases := []string{
+		forged,
+		forged + cursor[len(cursor)-44:],
+		cursor[:len(cursor)-1],
+	}
+	for _, c := range cases {
+		err := codec.Decode(c, &decoded)
+
+		s.ErrorIs(err, jsonkit.ErrInvalidCursor, c)
+		s.Equal(http.StatusBadRequest, jsonkit.ErrorStatus(err), c)
+	}
+
+	other := jsonkit.NewCursorCodec([]byte("other"))
+	s.ErrorIs(other.Decode(cursor, &decoded), jsonkit.ErrInvalidCursor)
+}
+
+func (s *PageSuite) TestCursor_DecodeRequest() {
+	codec := jsonkit.NewCursorCodec([]byte("secret"))
+	cursor, err := codec.Encode(pageCursor{AfterID: 7})
+	s.Nil(err)
+
+	var decoded pageCursor
+	ok, err := codec.DecodeRequest(httptest.NewRequest(http.MethodGet, "/users?cursor="+cursor, nil), &decoded)
+	s.Nil(err)
+	s.True(ok)
+	s.Equal(int64(7), decoded.AfterID)
+
+	ok, err = codec.DecodeRequest(httptest.NewRequest(http.MethodGet, "/users", nil), &decoded)
+	s.Nil(err)
+	s.False(ok)
+}
+
+func TestPageSuite(t *testing.T) {
+	suite.Run(t, new(PageSuite))
+}
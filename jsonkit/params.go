@@ -0,0 +1,345 @@
+package jsonkit
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Struct tags read by BindParams, one per field, e.g. `query:"page"`.
+// The `layout` tag sets the time.Parse layout of time.Time fields, RFC 3339 by default.
+const (
+	ParamPath   = "path"
+	ParamQuery  = "query"
+	ParamHeader = "header"
+	ParamForm   = "form"
+)
+
+var paramSources = []string{ParamPath, ParamQuery, ParamHeader, ParamForm}
+
+// maxFormMemory is the part of multipart forms kept in memory, the rest goes to temporary files.
+const maxFormMemory = 32 << 20
+
+// ParamError reports a request parameter that could not be converted to its field type.
+// BindParams returns it wrapped in a DecodeError, like malformed JSON bodies.
+type ParamError struct {
+	Source string // one of ParamPath, ParamQuery, ParamHeader or ParamForm
+	Name   string
+	Value  string
+	Err    error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s parameter %q: invalid value %q: %v", e.Source, e.Name, e.Value, e.Err)
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+// BindParams fills the tagged fields of the struct v points to from the request path values,
+// query string, headers and form body with the default codec.
+func BindParams(r *http.Request, v interface{}) error {
+	return defaultCodec.BindParams(r, v)
+}
+
+// BindParams fills the tagged fields of the struct v points to, see BindParams.
+// Repeated parameters fill slices, pointers are only set when the parameter is present,
+// and time.Time, time.Duration and encoding.TextUnmarshaler fields are parsed from text.
+// Fields of missing parameters are left untouched so defaults can be preset.
+// Form bodies are read within MaxBodyBytes.
+func (c *Codec) BindParams(r *http.Request, v interface{}) error {
+	return markRequestError(c.bindParams(r, v))
+}
+
+func (c *Codec) bindParams(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("jsonkit: BindParams expects a pointer to a struct, got %T", v)
+	}
+
+	fields, err := cachedParamFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if field.source == ParamForm {
+			if err := c.parseForm(r); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	for _, field := range fields {
+		values := paramValues(r, field.source, field.name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setParam(rv.Elem().FieldByIndex(field.index), values, field.layout); err != nil {
+			var numErr *strconv.NumError
+			if errors.As(err, &numErr) {
+				err = numErr.Err
+			}
+			return &DecodeError{Err: &ParamError{Source: field.source, Name: field.name, Value: values[0], Err: err}}
+		}
+	}
+	return nil
+}
+
+// parseForm parses url-encoded and multipart bodies within MaxBodyBytes.
+func (c *Codec) parseForm(r *http.Request) error {
+	if r.PostForm != nil {
+		return nil
+	}
+	if r.Body != nil {
+		body, err := c.requestBody(r)
+		if err != nil {
+			return err
+		}
+		r.Body = readCloser{Reader: body, Closer: r.Body}
+		r.Header.Del("Content-Encoding")
+	}
+
+	var err error
+	if formMediaType(r) == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxFormMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil && !errors.Is(err, ErrBodyTooLarge) {
+		return &DecodeError{Err: err}
+	}
+	return err
+}
+
+// bindTaggedParams binds parameters into v when it points to a struct with parameter tags,
+// or to a pointer to such a struct, which is allocated when nil.
+func (c *Codec) bindTaggedParams(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	t := rv.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields, err := cachedParamFields(t)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(t))
+		}
+		v = rv.Interface()
+	}
+	return c.BindParams(r, v)
+}
+
+// formMediaType returns the media type of form requests, empty for any other body.
+func formMediaType(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return mediaType
+	default:
+		return ""
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func paramValues(r *http.Request, source, name string) []string {
+	switch source {
+	case ParamPath:
+		if value := r.PathValue(name); value != "" {
+			return []string{value}
+		}
+		return nil
+	case ParamQuery:
+		return r.URL.Query()[name]
+	case ParamHeader:
+		return r.Header.Values(name)
+	default:
+		return r.PostForm[name]
+	}
+}
+
+type paramField struct {
+	index  []int
+	source string
+	name   string
+	layout string
+}
+
+var paramFieldsCache sync.Map // reflect.Type -> []paramField
+
+// cachedParamFields lists the tagged fields of a struct type, checking their types once.
+func cachedParamFields(t reflect.Type) ([]paramField, error) {
+	if cached, ok := paramFieldsCache.Load(t); ok {
+		return cached.([]paramField), nil
+	}
+
+	fields, err := collectParamFields(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	paramFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func collectParamFields(t reflect.Type, index []int) ([]paramField, error) {
+	var fields []paramField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		source, name := "", ""
+		for _, tag := range paramSources {
+			if value, ok := sf.Tag.Lookup(tag); ok {
+				source, name = tag, value
+				break
+			}
+		}
+
+		switch {
+		case source == "" && sf.Anonymous && sf.Type.Kind() == reflect.Struct:
+			embedded, err := collectParamFields(sf.Type, fieldIndex)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		case source == "" || name == "-":
+			continue
+		case !sf.IsExported():
+			return nil, fmt.Errorf("jsonkit: %s parameter field %s.%s is unexported", source, t, sf.Name)
+		}
+
+		if !isParamType(sf.Type, true) {
+			return nil, fmt.Errorf("jsonkit: unsupported %s parameter type %s for field %s.%s", source, sf.Type, t, sf.Name)
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, paramField{index: fieldIndex, source: source, name: name, layout: sf.Tag.Get("layout")})
+	}
+	return fields, nil
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// isParamType reports whether a parameter can be converted to t.
+func isParamType(t reflect.Type, allowSlice bool) bool {
+	if t == timeType || t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return isParamType(t.Elem(), false)
+	case reflect.Slice:
+		return allowSlice && isParamType(t.Elem(), false)
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// setParam converts the parameter values into v, slices take every value and other types the first one.
+func setParam(v reflect.Value, values []string, layout string) error {
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setParamValue(slice.Index(i), value, layout); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setParamValue(v, values[0], layout)
+}
+
+func setParamValue(v reflect.Value, value, layout string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setParamValue(elem.Elem(), value, layout); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package jsonkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+type paramLevel int
+
+func (l *paramLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type paramPaging struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit"`
+}
+
+type paramRequest struct {
+	paramPaging
+	ID        int64         `path:"id"`
+	Tags      []string      `query:"tag"`
+	Active    *bool         `query:"active"`
+	Missing   *int          `query:"missing"`
+	Since     time.Time     `query:"since"`
+	Day       time.Time     `query:"day" layout:"2006-01-02"`
+	Timeout   time.Duration `query:"timeout"`
+	Level     paramLevel    `query:"level"`
+	RequestID string        `header:"X-Request-ID"`
+	Name      string        `form:"name"`
+	Ignored   string        `query:"-"`
+	Body      string        `json:"body"`
+}
+
+type ParamsSuite struct {
+	suite.Suite
+}
+
+// serve routes the request through a ServeMux so path values are set.
+func (s *ParamsSuite) serve(pattern string, req *http.Request, handler http.HandlerFunc) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, handler)
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func (s *ParamsSuite) TestBindParams() {
+	form := url.Values{"name": {"John"}}
+	req := httptest.NewRequest(http.MethodPost,
+		"/users/42?page=2&tag=a&tag=b&active=true&since=2024-01-15T10:30:00Z&day=2024-02-01&timeout=1m30s&level=high&Ignored=x",
+		strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Request-ID", "abc")
+
+	var bound paramRequest
+	var err error
+	s.serve("POST /users/{id}", req, func(_ http.ResponseWriter, r *http.Request) {
+		bound = paramRequest{paramPaging: paramPaging{Limit: 20}}
+		err = jsonkit.BindParams(r, &bound)
+	})
+
+	s.Nil(err)
+	s.Equal(int64(42), bound.ID)
+	s.Equal(2, bound.Page)
+	s.Equal(20, bound.Limit) // preset default kept
+	s.Equal([]string{"a", "b"}, bound.Tags)
+	s.Equal(true, *bound.Active)
+	s.Nil(bound.Missing)
+	s.Equal(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), bound.Since)
+	s.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), bound.Day)
+	s.Equal(90*time.Second, bound.Timeout)
+	s.Equal(paramLevel(2), bound.Level)
+	s.Equal("abc", bound.RequestID)
+	s.Equal("John", bound.Name)
+	s.Empty(bound.Ignored)
+}
+
+func (s *ParamsSuite) TestBindParams_InvalidValue() {
+	cases := []struct {
+		target string
+		source string
+		name   string
+	}{
+		{"/users/x", jsonkit.ParamPath, "id"},
+		{"/users/1?page=two", jsonkit.ParamQuery, "page"},
+		{"/users/1?active=maybe", jsonkit.ParamQuery, "active"},
+		{"/users/1?since=yesterday", jsonkit.ParamQuery, "since"},
+		{"/users/1?level=medium", jsonkit.ParamQuery, "level"},
+		{"/users/1?page=99999999999999999999", jsonkit.ParamQuery, "page"},
+	}
+
+	for _, c := range cases {
+		var err error
+		s.serve("GET /users/{id}", httptest.NewRequest(http.MethodGet, c.target, nil), func(_ http.ResponseWriter, r *http.Request) {
+			err = jsonkit.BindParams(r, &paramRequest{})
+		})
+
+		var decodeErr *jsonkit.DecodeError
+		s.ErrorAs(err, &decodeErr, c.target)
+		var paramErr *jsonkit.ParamError
+		s.ErrorAs(err, &paramErr, c.target)
+		s.Equal(c.source, paramErr.Source, c.target)
+		s.Equal(c.name, paramErr.Name, c.target)
+		s.Equal(http.StatusBadRequest, jsonkit.ErrorStatus(err), c.target)
+	}
+}
+
+func (s *ParamsSuite) TestBindParams_UnsupportedTarget() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	s.NotNil(jsonkit.BindParams(req, paramRequest{}))
+
+	// Unsupported field types are programming errors, not bad input
+	err := jsonkit.BindParams(req, &struct {
+		Values map[string]string `query:"values"`
+	}{})
+	s.NotNil(err)
+	var decodeErr *jsonkit.DecodeError
+	s.False(errors.As(err, &decodeErr))
+}
+
+func (s *ParamsSuite) TestBindParams_FormTooLarge() {
+	codec := jsonkit.NewCodec()
+	codec.MaxBodyBytes = 8
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name="+strings.Repeat("x", 20)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	err := codec.BindParams(req, &paramRequest{})
+
+	s.ErrorIs(err, jsonkit.ErrBodyTooLarge)
+}
+
+func (s *ParamsSuite) TestHandler_BindsParams() {
+	h := jsonkit.NewHandler(func(_ context.Context, req paramRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"id": req.ID, "page": req.Page, "body": req.Body}, nil
+	})
+	mux := http.NewServeMux()
+	mux.Handle("POST /users/{id}", h)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/7?page=3", strings.NewReader(`{"body": "hi"}`)))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"id": 7, "page": 3, "body": "hi"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/7?page=x", nil))
+	s.Equal(http.StatusBadRequest, w.Code)
+}
+
+func (s *ParamsSuite) TestHandler_BindsParamsIntoPointer() {
+	h := jsonkit.NewHandler(func(_ context.Context, req *paramRequest) (map[string]interface{}, error) {
+		return map[string]interface{}{"id": req.ID, "page": req.Page, "body": req.Body}, nil
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/users/{id}", h)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7?page=3", nil))
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"id": 7, "page": 3, "body": ""}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/7?page=3", strings.NewReader(`{"body": "hi"}`)))
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"id": 7, "page": 3, "body": "hi"}`, w.Body.String())
+}
+
+func TestParamsSuite(t *testing.T) {
+	suite.Run(t, new(ParamsSuite))
+}
@@ -0,0 +1,484 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeJSONPatch is the media type of RFC 6902 JSON Patch documents.
+	ContentTypeJSONPatch = "application/json-patch+json"
+	// ContentTypeMergePatch is the media type of RFC 7396 JSON Merge Patch documents.
+	ContentTypeMergePatch = "application/merge-patch+json"
+)
+
+// JSON Patch operation names.
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpMove    = "move"
+	PatchOpCopy    = "copy"
+	PatchOpTest    = "test"
+)
+
+// documentCodec decodes raw documents keeping numbers as json.Number,
+// so patching doesn't lose precision on large integers.
+var documentCodec = &Codec{UseNumber: true}
+
+// operationCodec decodes JSON Patch documents, ignoring unknown operation members as RFC 6902 section 4 requires.
+var operationCodec = &Codec{}
+
+// PatchOperation is a single RFC 6902 JSON Patch operation.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is an RFC 6902 JSON Patch document.
+type Patch []PatchOperation
+
+// PatchError reports the operation of a JSON Patch that could not be applied.
+type PatchError struct {
+	Index int // 0-based index of the operation in the patch
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("json patch operation %d (%s %s): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// DecodePatch decodes and validates a JSON Patch document.
+func DecodePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := operationCodec.UnMarshal(data, &patch); err != nil {
+		return nil, err
+	}
+
+	for i, op := range patch {
+		if err := op.validate(); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return patch, nil
+}
+
+func (op PatchOperation) validate() error {
+	switch op.Op {
+	case PatchOpAdd, PatchOpReplace, PatchOpTest:
+		if len(op.Value) == 0 {
+			return fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+	case PatchOpMove, PatchOpCopy:
+		if _, err := parsePointer(op.From); err != nil {
+			return err
+		}
+	case PatchOpRemove:
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+
+	_, err := parsePointer(op.Path)
+	return err
+}
+
+// Apply applies the patch to a raw JSON document and returns the patched document.
+// The patch is atomic: if any operation fails, no result is returned.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var v interface{}
+	if err := documentCodec.UnMarshal(doc, &v); err != nil {
+		return nil, err
+	}
+
+	v, err := p.applyTo(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func (p Patch) applyTo(doc interface{}) (interface{}, error) {
+	for i, op := range p {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return doc, nil
+}
+
+func (op PatchOperation) apply(doc interface{}) (interface{}, error) {
+	if err := op.validate(); err != nil {
+		return nil, err
+	}
+	path, _ := parsePointer(op.Path)
+	from, _ := parsePointer(op.From)
+
+	var value interface{}
+	if len(op.Value) > 0 {
+		if err := documentCodec.UnMarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case PatchOpAdd:
+		return addValue(doc, path, value)
+	case PatchOpRemove:
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case PatchOpReplace:
+		return replaceValue(doc, path, value)
+	case PatchOpMove:
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("%w: cannot move %s into its own child", ErrInvalidPatch, op.From)
+		}
+		doc, moved, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, moved)
+	case PatchOpCopy:
+		copied, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, deepCopy(copied))
+	case PatchOpTest:
+		actual, err := getValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+}
+
+// ApplyPatch decodes patch and applies it to the raw JSON document doc.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	p, err := DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	return p.Apply(doc)
+}
+
+// ApplyPatchTo applies the patch to the Go value pointed to by v.
+// v is round-tripped through JSON and decoded with UnMarshal, so a patch
+// introducing unknown fields is rejected. v is left untouched on error.
+func ApplyPatchTo(v interface{}, patch Patch) error {
+	return defaultCodec.patchValue(v, patch.Apply)
+}
+
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch to the raw JSON document doc.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := documentCodec.UnMarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := documentCodec.UnMarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+// ApplyMergePatchTo applies a JSON Merge Patch to the Go value pointed to by v, see ApplyPatchTo.
+func ApplyMergePatchTo(v interface{}, patch []byte) error {
+	return defaultCodec.patchValue(v, func(doc []byte) ([]byte, error) {
+		return ApplyMergePatch(doc, patch)
+	})
+}
+
+// patchValue round-trips the value v points to through apply, decoding the result with the codec policy.
+func (c *Codec) patchValue(v interface{}, apply func(doc []byte) ([]byte, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("jsonkit: patch target must be a non-nil pointer, got %T", v)
+	}
+
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	patched, err := apply(doc)
+	if err != nil {
+		return err
+	}
+
+	// Decode into a fresh value so removed fields don't keep their old content
+	fresh := reflect.New(rv.Elem().Type())
+	if err := c.UnMarshal(patched, fresh.Interface()); err != nil {
+		return err
+	}
+	rv.Elem().Set(fresh.Elem())
+	return nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], value)
+	}
+	return t
+}
+
+// CreatePatch diffs two raw JSON documents and returns a JSON Patch turning original into modified.
+func CreatePatch(original, modified []byte) (Patch, error) {
+	var from, to interface{}
+	if err := documentCodec.UnMarshal(original, &from); err != nil {
+		return nil, err
+	}
+	if err := documentCodec.UnMarshal(modified, &to); err != nil {
+		return nil, err
+	}
+
+	patch := Patch{}
+	if err := diff(&patch, nil, from, to); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+func diff(patch *Patch, tokens []string, from, to interface{}) error {
+	if jsonEqual(from, to) {
+		return nil
+	}
+
+	path := func(token string) []string {
+		return append(slices.Clip(tokens), token)
+	}
+
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(f) {
+			if _, ok := t[key]; !ok {
+				*patch = append(*patch, PatchOperation{Op: PatchOpRemove, Path: formatPointer(path(key))})
+			}
+		}
+		for _, key := range sortedKeys(t) {
+			fromValue, ok := f[key]
+			if !ok {
+				if err := patch.add(PatchOpAdd, path(key), t[key]); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := diff(patch, path(key), fromValue, t[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		common := min(len(f), len(t))
+		for i := 0; i < common; i++ {
+			if err := diff(patch, path(strconv.Itoa(i)), f[i], t[i]); err != nil {
+				return err
+			}
+		}
+		// Remove from the end so earlier indexes stay valid
+		for i := len(f) - 1; i >= common; i-- {
+			*patch = append(*patch, PatchOperation{Op: PatchOpRemove, Path: formatPointer(path(strconv.Itoa(i)))})
+		}
+		for i := common; i < len(t); i++ {
+			if err := patch.add(PatchOpAdd, path("-"), t[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return patch.add(PatchOpReplace, tokens, to)
+}
+
+func (p *Patch) add(op string, tokens []string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, PatchOperation{Op: op, Path: formatPointer(tokens), Value: raw})
+	return nil
+}
+
+// CreateMergePatch diffs two raw JSON documents and returns a JSON Merge Patch turning original into modified.
+// Merge patches cannot set a member to null, such changes are expressed as removals.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	var from, to interface{}
+	if err := documentCodec.UnMarshal(original, &from); err != nil {
+		return nil, err
+	}
+	if err := documentCodec.UnMarshal(modified, &to); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeDiff(from, to))
+}
+
+func mergeDiff(from, to interface{}) interface{} {
+	f, fromObject := from.(map[string]interface{})
+	t, toObject := to.(map[string]interface{})
+	if !fromObject || !toObject {
+		return to
+	}
+
+	patch := map[string]interface{}{}
+	for key := range f {
+		if _, ok := t[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	for key, value := range t {
+		if fromValue, ok := f[key]; !ok || !jsonEqual(fromValue, value) {
+			patch[key] = mergeDiff(fromValue, value)
+		}
+	}
+	return patch
+}
+
+// BindPatch reads a JSON Patch or JSON Merge Patch request body, selected by its Content-Type,
+// and applies it to the Go value pointed to by v with the default codec.
+func BindPatch(r *http.Request, v interface{}) error {
+	return defaultCodec.BindPatch(r, v)
+}
+
+// BindPatch applies the JSON Patch or JSON Merge Patch request body to the Go value pointed to by v,
+// see BindPatch. The body is read within MaxBodyBytes and the patched value decoded with the codec policy.
+func (c *Codec) BindPatch(r *http.Request, v interface{}) error {
+	return markRequestError(c.bindPatch(r, v))
+}
+
+func (c *Codec) bindPatch(r *http.Request, v interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedMediaType, err)
+	}
+
+	switch mediaType {
+	case ContentTypeJSONPatch:
+		data, err := c.readBody(r)
+		if err != nil {
+			return err
+		}
+		patch, err := DecodePatch(data)
+		if err != nil {
+			return err
+		}
+		return c.patchValue(v, patch.Apply)
+	case ContentTypeMergePatch:
+		data, err := c.readBody(r)
+		if err != nil {
+			return err
+		}
+		return c.patchValue(v, func(doc []byte) ([]byte, error) {
+			return ApplyMergePatch(doc, data)
+		})
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+}
+
+// jsonEqual compares two decoded documents, treating numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		xf, errX := x.Float64()
+		yf, errY := y.Float64()
+		return errX == nil && errY == nil && xf == yf
+	default:
+		return a == b
+	}
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(x))
+		for key, value := range x {
+			c[key] = deepCopy(value)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(x))
+		for i, value := range x {
+			c[i] = deepCopy(value)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,263 @@
+package jsonkit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+type patchUser struct {
+	Name     string   `json:"name"`
+	Nickname string   `json:"nickname,omitempty"`
+	Age      int      `json:"age"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type PatchSuite struct {
+	suite.Suite
+}
+
+func (s *PatchSuite) TestApplyPatch_Operations() {
+	cases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"add array end", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{"add null value", `{}`, `[{"op":"add","path":"/foo","value":null}]`, `{"foo":null}`},
+		{"add whole document", `{"foo":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move member", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`, `{"foo":{"bar":1},"baz":{"bar":2}}`},
+		{"test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"escaped path", `{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`},
+		{"unknown member ignored", `{"baz":"qux"}`, `[{"op":"replace","path":"/baz","value":"boo","comment":"rename"}]`, `{"baz":"boo"}`},
+		{"large number", `{"id":12345678901234567890}`, `[{"op":"add","path":"/x","value":1}]`, `{"id":12345678901234567890,"x":1}`},
+	}
+
+	for _, c := range cases {
+		result, err := jsonkit.ApplyPatch([]byte(c.doc), []byte(c.patch))
+		s.Nil(err, c.name)
+		s.JSONEq(c.expected, string(result), c.name)
+	}
+}
+
+func (s *PatchSuite) TestApplyPatch_Errors() {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		err   error
+	}{
+		{"missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, jsonkit.ErrPathNotFound},
+		{"missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, jsonkit.ErrPathNotFound},
+		{"index out of range", `{"foo":[1]}`, `[{"op":"add","path":"/foo/5","value":2}]`, jsonkit.ErrPathNotFound},
+		{"replace missing", `{}`, `[{"op":"replace","path":"/foo","value":1}]`, jsonkit.ErrPathNotFound},
+		{"test failed", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, jsonkit.ErrTestFailed},
+		{"unknown op", `{}`, `[{"op":"frob","path":"/foo"}]`, jsonkit.ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/foo"}]`, jsonkit.ErrInvalidPatch},
+		{"invalid pointer", `{}`, `[{"op":"add","path":"foo","value":1}]`, jsonkit.ErrInvalidPointer},
+		{"leading zero index", `{"foo":[1,2]}`, `[{"op":"remove","path":"/foo/01"}]`, jsonkit.ErrInvalidPointer},
+		{"move into child", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, jsonkit.ErrInvalidPatch},
+	}
+
+	for _, c := range cases {
+		result, err := jsonkit.ApplyPatch([]byte(c.doc), []byte(c.patch))
+		s.ErrorIs(err, c.err, c.name)
+		s.Nil(result, c.name)
+	}
+}
+
+func (s *PatchSuite) TestApplyPatch_ErrorReportsOperation() {
+	patch := `[{"op":"add","path":"/a","value":1},{"op":"remove","path":"/missing"}]`
+
+	_, err := jsonkit.ApplyPatch([]byte(`{}`), []byte(patch))
+
+	var patchErr *jsonkit.PatchError
+	s.True(errors.As(err, &patchErr))
+	s.Equal(1, patchErr.Index)
+	s.Equal("remove", patchErr.Op)
+	s.Equal("/missing", patchErr.Path)
+}
+
+func (s *PatchSuite) TestApplyPatchTo_Success() {
+	user := patchUser{Name: "John", Nickname: "JJ", Age: 30}
+	patch, err := jsonkit.DecodePatch([]byte(`[
+		{"op":"remove","path":"/nickname"},
+		{"op":"replace","path":"/age","value":31},
+		{"op":"add","path":"/tags","value":["admin"]}
+	]`))
+	s.Nil(err)
+
+	err = jsonkit.ApplyPatchTo(&user, patch)
+
+	s.Nil(err)
+	s.Equal(patchUser{Name: "John", Age: 31, Tags: []string{"admin"}}, user)
+}
+
+func (s *PatchSuite) TestApplyPatchTo_UnknownField() {
+	user := patchUser{Name: "John"}
+	patch := jsonkit.Patch{{Op: "add", Path: "/extra", Value: []byte(`1`)}}
+
+	err := jsonkit.ApplyPatchTo(&user, patch)
+
+	s.NotNil(err)
+	s.Equal(patchUser{Name: "John"}, user)
+}
+
+func (s *PatchSuite) TestApplyPatchTo_NotPointer() {
+	err := jsonkit.ApplyPatchTo(patchUser{}, jsonkit.Patch{})
+
+	s.NotNil(err)
+}
+
+func (s *PatchSuite) TestApplyMergePatch() {
+	cases := []struct {
+		doc      string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, c := range cases {
+		result, err := jsonkit.ApplyMergePatch([]byte(c.doc), []byte(c.patch))
+		s.Nil(err)
+		s.JSONEq(c.expected, string(result), c.patch)
+	}
+}
+
+func (s *PatchSuite) TestApplyMergePatchTo_Success() {
+	user := patchUser{Name: "John", Nickname: "JJ", Age: 30}
+
+	err := jsonkit.ApplyMergePatchTo(&user, []byte(`{"nickname":null,"age":31}`))
+
+	s.Nil(err)
+	s.Equal(patchUser{Name: "John", Age: 31}, user)
+}
+
+func (s *PatchSuite) TestCreatePatch_RoundTrip() {
+	cases := []struct {
+		original string
+		modified string
+	}{
+		{`{"a":1,"b":2}`, `{"a":1,"c":3}`},
+		{`{"a":{"b":[1,2,3]}}`, `{"a":{"b":[1,5]}}`},
+		{`{"a":[1]}`, `{"a":[1,2,{"x":"y"}]}`},
+		{`{"a/b":{"m~n":1}}`, `{"a/b":{"m~n":2}}`},
+		{`{"a":"b"}`, `[1,2]`},
+		{`{"a":1.0}`, `{"a":1}`},
+	}
+
+	for _, c := range cases {
+		patch, err := jsonkit.CreatePatch([]byte(c.original), []byte(c.modified))
+		s.Nil(err)
+
+		result, err := patch.Apply([]byte(c.original))
+		s.Nil(err)
+		s.JSONEq(c.modified, string(result), c.original)
+	}
+}
+
+func (s *PatchSuite) TestCreatePatch_Equal() {
+	patch, err := jsonkit.CreatePatch([]byte(`{"a":[1,{"b":2}]}`), []byte(`{"a":[1,{"b":2}]}`))
+
+	s.Nil(err)
+	s.Empty(patch)
+}
+
+func (s *PatchSuite) TestCreateMergePatch_RoundTrip() {
+	original := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`
+	modified := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`
+
+	patch, err := jsonkit.CreateMergePatch([]byte(original), []byte(modified))
+	s.Nil(err)
+	s.JSONEq(`{"title":"Hello!","author":{"familyName":null},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`, string(patch))
+
+	result, err := jsonkit.ApplyMergePatch([]byte(original), patch)
+	s.Nil(err)
+	s.JSONEq(modified, string(result))
+}
+
+func (s *PatchSuite) TestBindPatch_JSONPatch() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[{"op":"replace","path":"/name","value":"Jane"}]`))
+	req.Header.Set("Content-Type", jsonkit.ContentTypeJSONPatch)
+	user := patchUser{Name: "John", Age: 30}
+
+	err := jsonkit.BindPatch(req, &user)
+
+	s.Nil(err)
+	s.Equal(patchUser{Name: "Jane", Age: 30}, user)
+}
+
+func (s *PatchSuite) TestBindPatch_MergePatch() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"age":31}`))
+	req.Header.Set("Content-Type", jsonkit.ContentTypeMergePatch+"; charset=utf-8")
+	user := patchUser{Name: "John", Age: 30}
+
+	err := jsonkit.BindPatch(req, &user)
+
+	s.Nil(err)
+	s.Equal(patchUser{Name: "John", Age: 31}, user)
+}
+
+func (s *PatchSuite) TestCodecBindPatch_BodyLimit() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[{"op":"replace","path":"/name","value":"Jane"}]`))
+	req.Header.Set("Content-Type", jsonkit.ContentTypeJSONPatch)
+	codec := jsonkit.NewCodec()
+	codec.MaxBodyBytes = 16
+	user := patchUser{Name: "John"}
+
+	err := codec.BindPatch(req, &user)
+
+	s.ErrorIs(err, jsonkit.ErrBodyTooLarge)
+	s.Equal(patchUser{Name: "John"}, user)
+}
+
+func (s *PatchSuite) TestCodecBindPatch_AllowUnknownFields() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"age":31,"extra":true}`))
+	req.Header.Set("Content-Type", jsonkit.ContentTypeMergePatch)
+	codec := jsonkit.NewCodec()
+	codec.DisallowUnknownFields = false
+	user := patchUser{Name: "John", Age: 30}
+
+	err := codec.BindPatch(req, &user)
+
+	s.Nil(err)
+	s.Equal(patchUser{Name: "John", Age: 31}, user)
+}
+
+func (s *PatchSuite) TestBindPatch_UnsupportedMediaType() {
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"age":31}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	err := jsonkit.BindPatch(req, &patchUser{})
+
+	s.ErrorIs(err, jsonkit.ErrUnsupportedMediaType)
+}
+
+func TestPatchSuite(t *testing.T) {
+	suite.Run(t, new(PatchSuite))
+}
@@ -0,0 +1,408 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is an RFC 6901 JSON Pointer, stored as its unescaped reference tokens.
+// The empty Pointer refers to the whole document.
+type Pointer []string
+
+// PointerError reports a JSON Pointer that could not be resolved or applied.
+type PointerError struct {
+	Pointer string
+	Err     error
+}
+
+func (e *PointerError) Error() string {
+	return fmt.Sprintf("json pointer %q: %v", e.Pointer, e.Err)
+}
+
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePointer parses the string form of a JSON Pointer, unescaping ~1 to / and ~0 to ~.
+func ParsePointer(pointer string) (Pointer, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return Pointer(tokens), nil
+}
+
+// NewPointer creates a Pointer from unescaped reference tokens.
+func NewPointer(tokens ...string) Pointer {
+	return Pointer(tokens)
+}
+
+// String returns the escaped string form of the pointer.
+func (p Pointer) String() string {
+	return formatPointer(p)
+}
+
+// Append returns a new Pointer with tokens added to the end of p.
+func (p Pointer) Append(tokens ...string) Pointer {
+	return append(append(Pointer{}, p...), tokens...)
+}
+
+// Get resolves the pointer against a decoded document.
+func (p Pointer) Get(doc interface{}) (interface{}, error) {
+	value, err := getValue(doc, p)
+	if err != nil {
+		return nil, p.wrapErr(err)
+	}
+	return value, nil
+}
+
+// Set stores value at the pointer and returns the updated document, doc may be modified in place.
+// Object members are created or overwritten, array elements are replaced,
+// and "-" or the array length appends. The parent must already exist.
+func (p Pointer) Set(doc interface{}, value interface{}) (interface{}, error) {
+	updated, err := setValue(doc, p, value)
+	if err != nil {
+		return nil, p.wrapErr(err)
+	}
+	return updated, nil
+}
+
+// Delete removes the value at the pointer and returns the updated document, doc may be modified in place.
+func (p Pointer) Delete(doc interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return nil, p.wrapErr(fmt.Errorf("%w: cannot delete the whole document", ErrInvalidPointer))
+	}
+
+	updated, _, err := removeValue(doc, p)
+	if err != nil {
+		return nil, p.wrapErr(err)
+	}
+	return updated, nil
+}
+
+func (p Pointer) wrapErr(err error) error {
+	return &PointerError{Pointer: p.String(), Err: err}
+}
+
+// GetPointer resolves pointer in the raw JSON document data and returns the raw value.
+func GetPointer(data []byte, pointer string) (json.RawMessage, error) {
+	p, doc, err := decodePointerDocument(data, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.Get(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// SetPointer stores value at pointer in the raw JSON document data, see Pointer.Set.
+func SetPointer(data []byte, pointer string, value interface{}) ([]byte, error) {
+	p, doc, err := decodePointerDocument(data, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip value so structs and raw messages become plain documents
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := documentCodec.UnMarshal(raw, &v); err != nil {
+		return nil, err
+	}
+
+	updated, err := p.Set(doc, v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(updated)
+}
+
+// DeletePointer removes the value at pointer from the raw JSON document data.
+func DeletePointer(data []byte, pointer string) ([]byte, error) {
+	p, doc, err := decodePointerDocument(data, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := p.Delete(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(updated)
+}
+
+func decodePointerDocument(data []byte, pointer string) (Pointer, interface{}, error) {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc interface{}
+	if err := documentCodec.UnMarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	return p, doc, nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+// The empty pointer refers to the whole document and returns no tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q must start with /", ErrInvalidPointer, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		unescaped, err := unescapeToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q %v", ErrInvalidPointer, pointer, err)
+		}
+		tokens[i] = unescaped
+	}
+	return tokens, nil
+}
+
+// formatPointer joins reference tokens into a JSON Pointer, escaping ~ and /.
+func formatPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapeToken(token))
+	}
+	return sb.String()
+}
+
+func escapeToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+func unescapeToken(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			sb.WriteByte(token[i])
+			continue
+		}
+		if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+			return "", fmt.Errorf("has invalid escape in token %q", token)
+		}
+		if token[i+1] == '0' {
+			sb.WriteByte('~')
+		} else {
+			sb.WriteByte('/')
+		}
+		i++
+	}
+	return sb.String(), nil
+}
+
+// arrayIndex parses an array reference token. With allowEnd set,
+// "-" and an index equal to length address the position after the last element.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if allowEnd {
+			return length, nil
+		}
+		return 0, fmt.Errorf("%w: index - is past the end of the array", ErrPathNotFound)
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPointer, token)
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPointer, token)
+	}
+	if index > length || (index == length && !allowEnd) {
+		return 0, fmt.Errorf("%w: index %d out of range", ErrPathNotFound, index)
+	}
+	return index, nil
+}
+
+// getValue resolves tokens against a decoded document.
+func getValue(doc interface{}, tokens []string) (interface{}, error) {
+	node := doc
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrPathNotFound, token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("%w: cannot traverse %T with %q", ErrPathNotFound, node, token)
+		}
+	}
+	return node, nil
+}
+
+// updateParent walks to the container holding the last token and replaces it by the result of update.
+// Arrays are values in Go, so every container on the way is re-assigned to its parent.
+func updateParent(node interface{}, tokens []string, update func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return update(node, tokens[0])
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: member %q does not exist", ErrPathNotFound, tokens[0])
+		}
+		updated, err := updateParent(child, tokens[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		n[tokens[0]] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(tokens[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateParent(n[index], tokens[1:], update)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = updated
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot traverse %T with %q", ErrPathNotFound, node, tokens[0])
+	}
+}
+
+// addValue inserts value at tokens, creating an object member or shifting array elements.
+func addValue(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:index], append([]interface{}{value}, c[index:]...)...), nil
+		default:
+			return nil, fmt.Errorf("%w: cannot add %q to %T", ErrPathNotFound, token, container)
+		}
+	})
+}
+
+// replaceValue overwrites the existing value at tokens.
+func replaceValue(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrPathNotFound, token)
+			}
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			c[index] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%w: cannot replace %q in %T", ErrPathNotFound, token, container)
+		}
+	})
+}
+
+// setValue creates or overwrites the value at tokens.
+func setValue(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			if index == len(c) {
+				return append(c, value), nil
+			}
+			c[index] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%w: cannot set %q in %T", ErrPathNotFound, token, container)
+		}
+	})
+}
+
+// removeValue deletes the value at tokens and returns the updated document and the removed value.
+func removeValue(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+
+	var removed interface{}
+	updated, err := updateParent(doc, tokens, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			value, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q does not exist", ErrPathNotFound, token)
+			}
+			removed = value
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[index]
+			return append(c[:index], c[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: cannot remove %q from %T", ErrPathNotFound, token, container)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, removed, nil
+}
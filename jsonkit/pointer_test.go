@@ -0,0 +1,218 @@
+package jsonkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+// rfc6901Doc is the example document of RFC 6901 section 5
+const rfc6901Doc = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+type PointerSuite struct {
+	suite.Suite
+}
+
+func (s *PointerSuite) TestGetPointer_RFCExamples() {
+	cases := map[string]string{
+		"":       rfc6901Doc,
+		"/foo":   `["bar","baz"]`,
+		"/foo/0": `"bar"`,
+		"/":      `0`,
+		"/a~1b":  `1`,
+		"/c%d":   `2`,
+		"/e^f":   `3`,
+		"/g|h":   `4`,
+		"/i\\j":  `5`,
+		"/k\"l":  `6`,
+		"/ ":     `7`,
+		"/m~0n":  `8`,
+	}
+
+	for pointer, expected := range cases {
+		value, err := jsonkit.GetPointer([]byte(rfc6901Doc), pointer)
+		s.Nil(err, pointer)
+		s.JSONEq(expected, string(value), pointer)
+	}
+}
+
+func (s *PointerSuite) TestGetPointer_NotFound() {
+	cases := []string{"/missing", "/foo/2", "/foo/-", "/foo/0/bar", "/a~1b/c"}
+
+	for _, pointer := range cases {
+		_, err := jsonkit.GetPointer([]byte(rfc6901Doc), pointer)
+		s.ErrorIs(err, jsonkit.ErrPathNotFound, pointer)
+
+		var pointerErr *jsonkit.PointerError
+		s.True(errors.As(err, &pointerErr), pointer)
+		s.Equal(pointer, pointerErr.Pointer)
+	}
+}
+
+func (s *PointerSuite) TestParsePointer_Invalid() {
+	cases := []string{"foo", "/m~2n", "/m~"}
+
+	for _, pointer := range cases {
+		_, err := jsonkit.ParsePointer(pointer)
+		s.ErrorIs(err, jsonkit.ErrInvalidPointer, pointer)
+	}
+}
+
+func (s *PointerSuite) TestPointer_String() {
+	p := jsonkit.NewPointer("a/b", "m~n", "0")
+
+	s.Equal("/a~1b/m~0n/0", p.String())
+	s.Equal("", jsonkit.NewPointer().String())
+
+	parsed, err := jsonkit.ParsePointer(p.String())
+	s.Nil(err)
+	s.Equal(p, parsed)
+}
+
+func (s *PointerSuite) TestPointer_Append() {
+	base := jsonkit.NewPointer("items")
+
+	first := base.Append("0")
+	second := base.Append("1")
+
+	s.Equal("/items/0", first.String())
+	s.Equal("/items/1", second.String())
+	s.Equal("/items", base.String())
+}
+
+func (s *PointerSuite) TestPointer_GetSetDelete() {
+	var doc interface{}
+	s.Nil(json.Unmarshal([]byte(`{"user":{"name":"John","tags":["a"]}}`), &doc))
+
+	name, err := jsonkit.NewPointer("user", "name").Get(doc)
+	s.Nil(err)
+	s.Equal("John", name)
+
+	doc, err = jsonkit.NewPointer("user", "tags", "-").Set(doc, "b")
+	s.Nil(err)
+	doc, err = jsonkit.NewPointer("user", "name").Delete(doc)
+	s.Nil(err)
+
+	data, err := json.Marshal(doc)
+	s.Nil(err)
+	s.JSONEq(`{"user":{"tags":["a","b"]}}`, string(data))
+}
+
+func (s *PointerSuite) TestSetPointer_Success() {
+	cases := []struct {
+		pointer  string
+		value    interface{}
+		expected string
+	}{
+		{"/name", "Jane", `{"name":"Jane","tags":["a","b"]}`},
+		{"/age", 30, `{"name":"John","age":30,"tags":["a","b"]}`},
+		{"/tags/0", "z", `{"name":"John","tags":["z","b"]}`},
+		{"/tags/-", "c", `{"name":"John","tags":["a","b","c"]}`},
+		{"/tags/2", "c", `{"name":"John","tags":["a","b","c"]}`},
+		{"/address", map[string]string{"city": "Paris"}, `{"name":"John","tags":["a","b"],"address":{"city":"Paris"}}`},
+		{"", []int{1}, `[1]`},
+	}
+
+	for _, c := range cases {
+		result, err := jsonkit.SetPointer([]byte(`{"name":"John","tags":["a","b"]}`), c.pointer, c.value)
+		s.Nil(err, c.pointer)
+		s.JSONEq(c.expected, string(result), c.pointer)
+	}
+}
+
+func (s *PointerSuite) TestSetPointer_MissingParent() {
+	_, err := jsonkit.SetPointer([]byte(`{}`), "/address/city", "Paris")
+
+	s.ErrorIs(err, jsonkit.ErrPathNotFound)
+}
+
+func (s *PointerSuite) TestDeletePointer() {
+	result, err := jsonkit.DeletePointer([]byte(`{"a":{"b":[1,2,3]}}`), "/a/b/1")
+	s.Nil(err)
+	s.JSONEq(`{"a":{"b":[1,3]}}`, string(result))
+
+	_, err = jsonkit.DeletePointer([]byte(`{"a":1}`), "/b")
+	s.ErrorIs(err, jsonkit.ErrPathNotFound)
+
+	_, err = jsonkit.DeletePointer([]byte(`{"a":1}`), "")
+	s.ErrorIs(err, jsonkit.ErrInvalidPointer)
+}
+
+func (s *PointerSuite) TestDecodeError_Pointer() {
+	type item struct {
+		Qty int `json:"qty"`
+	}
+	type order struct {
+		Items []item `json:"items"`
+	}
+
+	var o order
+	err := jsonkit.UnMarshal([]byte(`{"items":[{"qty":1},{"qty":"two"}]}`), &o)
+
+	var decodeErr *jsonkit.DecodeError
+	s.True(errors.As(err, &decodeErr))
+	var typeErr *json.UnmarshalTypeError
+	s.True(errors.As(err, &typeErr))
+	if strings.Contains(typeErr.Field, "1") {
+		s.Equal("/items/1/qty", decodeErr.Pointer.String())
+	} else {
+		// The legacy encoding/json leaves the array index out, the location can't be recovered
+		s.Empty(decodeErr.Pointer)
+	}
+}
+
+func (s *PointerSuite) TestDecodeError_PointerEscapedKey() {
+	var v struct {
+		A struct {
+			B int `json:"m~n"`
+		} `json:"a/b"`
+	}
+	err := jsonkit.UnMarshal([]byte(`{"a/b":{"m~n":"two"}}`), &v)
+
+	var decodeErr *jsonkit.DecodeError
+	s.True(errors.As(err, &decodeErr))
+	s.Equal(jsonkit.Pointer{"a/b", "m~n"}, decodeErr.Pointer)
+	s.Equal("/a~1b/m~0n", decodeErr.Pointer.String())
+}
+
+func (s *PointerSuite) TestDecodeError_PointerDottedField() {
+	var v struct {
+		Limits struct {
+			CPU int `json:"cpu.max"`
+		} `json:"limits"`
+	}
+	err := jsonkit.UnMarshal([]byte(`{"limits":{"cpu.max":"two"}}`), &v)
+
+	var decodeErr *jsonkit.DecodeError
+	s.True(errors.As(err, &decodeErr))
+	s.Equal(jsonkit.Pointer{"limits", "cpu.max"}, decodeErr.Pointer)
+}
+
+func (s *PointerSuite) TestDecodeError_PointerAmbiguousKey() {
+	var v map[string]map[string]int
+	err := jsonkit.UnMarshal([]byte(`{"a.b":{"c":"two"}}`), &v)
+
+	// "a.b.c" may be {"a":{"b.c":…}} as well, so no pointer is reported
+	var decodeErr *jsonkit.DecodeError
+	s.True(errors.As(err, &decodeErr))
+	s.Empty(decodeErr.Pointer)
+}
+
+func TestPointerSuite(t *testing.T) {
+	suite.Run(t, new(PointerSuite))
+}
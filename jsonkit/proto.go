@@ -0,0 +1,195 @@
+package jsonkit
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Media types understood by BindProtoRequest and ProtoResponse.
+const (
+	ContentTypeJSON      = "application/json"
+	ContentTypeProtobuf  = "application/x-protobuf"
+	ContentTypeProtoText = "text/x-protobuf"
+)
+
+// protoMediaTypes maps the accepted aliases to the canonical Protobuf media types.
+var protoMediaTypes = map[string]string{
+	ContentTypeJSON:                   ContentTypeJSON,
+	ContentTypeProtobuf:               ContentTypeProtobuf,
+	"application/protobuf":            ContentTypeProtobuf,
+	"application/vnd.google.protobuf": ContentTypeProtobuf,
+	ContentTypeProtoText:              ContentTypeProtoText,
+	"application/x-protobuf-text":     ContentTypeProtoText,
+}
+
+// BindProtoRequest decodes the request body into a Protobuf message,
+// choosing binary, protojson or text format from the Content-Type header.
+// A missing Content-Type is treated as JSON.
+func BindProtoRequest(r *http.Request, v proto.Message) error {
+	return defaultCodec.BindProtoRequest(r, v)
+}
+
+// ProtoResponse writes a Protobuf message in the format preferred by the request Accept header,
+// falling back to JSON when no supported format is acceptable.
+func ProtoResponse(w http.ResponseWriter, r *http.Request, statusCode int, v proto.Message) error {
+	return defaultCodec.ProtoResponse(w, r, statusCode, v)
+}
+
+// BindProtoRequest decodes the request body into a Protobuf message, see BindProtoRequest.
+func (c *Codec) BindProtoRequest(r *http.Request, v proto.Message) error {
+	return markRequestError(c.bindProtoRequest(r, v))
+}
+
+func (c *Codec) bindProtoRequest(r *http.Request, v proto.Message) error {
+	mediaType := ContentTypeJSON
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedMediaType, err)
+		}
+		var ok bool
+		if mediaType, ok = protoMediaTypes[parsed]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, parsed)
+		}
+	}
+
+	data, err := c.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case ContentTypeProtobuf:
+		err = proto.UnmarshalOptions{
+			AllowPartial:   c.ProtoUnmarshalOptions.AllowPartial,
+			DiscardUnknown: c.discardUnknown(),
+			RecursionLimit: c.MaxDepth,
+		}.Unmarshal(data, v)
+		if err == nil && !c.discardUnknown() {
+			// the binary decoder keeps unknown fields instead of failing on them
+			err = checkUnknownFields(v.ProtoReflect())
+		}
+	case ContentTypeProtoText:
+		err = prototext.UnmarshalOptions{
+			AllowPartial:   c.ProtoUnmarshalOptions.AllowPartial,
+			DiscardUnknown: c.discardUnknown(),
+		}.Unmarshal(data, v)
+	default:
+		return c.UnMarshalProto(data, v)
+	}
+	if err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+// ProtoResponse writes a Protobuf message in the format negotiated from the request, see ProtoResponse.
+func (c *Codec) ProtoResponse(w http.ResponseWriter, r *http.Request, statusCode int, v proto.Message) error {
+	mediaType := negotiateProtoMediaType(r.Header.Values("Accept"))
+
+	var (
+		data []byte
+		err  error
+	)
+	switch mediaType {
+	case ContentTypeProtobuf:
+		data, err = proto.Marshal(v)
+	case ContentTypeProtoText:
+		data, err = prototext.Marshal(v)
+	default:
+		data, err = c.MarshalProto(v)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(data)
+	return err
+}
+
+// checkUnknownFields reports the first unknown field kept in m or its nested messages.
+func checkUnknownFields(m protoreflect.Message) error {
+	if unknown := m.GetUnknown(); len(unknown) > 0 {
+		num, _, _ := protowire.ConsumeTag(unknown)
+		return fmt.Errorf("proto: unknown field %d in %s", num, m.Descriptor().FullName())
+	}
+
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+					err = checkUnknownFields(value.Message())
+					return err == nil
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				list := v.List()
+				for i := 0; i < list.Len() && err == nil; i++ {
+					err = checkUnknownFields(list.Get(i).Message())
+				}
+			}
+		case fd.Message() != nil:
+			err = checkUnknownFields(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+// negotiateProtoMediaType picks the supported media type with the highest quality in the Accept headers.
+func negotiateProtoMediaType(accept []string) string {
+	type candidate struct {
+		mediaType string
+		quality   float64
+	}
+
+	var candidates []candidate
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			if quality <= 0 {
+				continue
+			}
+
+			if mediaType == "*/*" || mediaType == "application/*" {
+				mediaType = ContentTypeJSON
+			}
+			if canonical, ok := protoMediaTypes[mediaType]; ok {
+				candidates = append(candidates, candidate{mediaType: canonical, quality: quality})
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ContentTypeJSON
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].mediaType
+}
@@ -0,0 +1,178 @@
+package jsonkit_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+type ProtoSuite struct {
+	suite.Suite
+}
+
+func (s *ProtoSuite) newRequest(contentType string, body []byte) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_Formats() {
+	user := &pb.User{Name: "John", Age: 30, City: "New York"}
+	binary, err := proto.Marshal(user)
+	s.Nil(err)
+	text, err := prototext.Marshal(user)
+	s.Nil(err)
+
+	cases := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"", []byte(`{"name": "John", "age": 30, "city": "New York"}`)},
+		{"application/json; charset=utf-8", []byte(`{"name": "John", "age": 30, "city": "New York"}`)},
+		{jsonkit.ContentTypeProtobuf, binary},
+		{"application/protobuf", binary},
+		{jsonkit.ContentTypeProtoText, text},
+	}
+
+	for _, c := range cases {
+		req := s.newRequest(c.contentType, c.body)
+		msg := &pb.User{}
+
+		err := jsonkit.BindProtoRequest(req, msg)
+
+		s.Nil(err, c.contentType)
+		s.True(proto.Equal(user, msg), c.contentType)
+
+		// Body can be read again
+		body, err := io.ReadAll(req.Body)
+		s.Nil(err)
+		s.Equal(c.body, body)
+	}
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_UnsupportedMediaType() {
+	req := s.newRequest("application/xml", []byte(`<user/>`))
+
+	err := jsonkit.BindProtoRequest(req, &pb.User{})
+
+	s.ErrorIs(err, jsonkit.ErrUnsupportedMediaType)
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_DecodeError() {
+	cases := []struct {
+		contentType string
+		body        []byte
+	}{
+		{jsonkit.ContentTypeJSON, []byte(`{"name": `)},
+		{jsonkit.ContentTypeProtobuf, []byte{0xff, 0xff}},
+		{jsonkit.ContentTypeProtoText, []byte(`name: `)},
+	}
+
+	for _, c := range cases {
+		err := jsonkit.BindProtoRequest(s.newRequest(c.contentType, c.body), &pb.User{})
+
+		var decodeErr *jsonkit.DecodeError
+		s.True(errors.As(err, &decodeErr), c.contentType)
+	}
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_UnknownFields() {
+	binary, err := proto.Marshal(&pb.User{Name: "John"})
+	s.Nil(err)
+	binary = protowire.AppendTag(binary, 42, protowire.VarintType)
+	binary = protowire.AppendVarint(binary, 1)
+
+	cases := []struct {
+		contentType string
+		body        []byte
+	}{
+		{jsonkit.ContentTypeJSON, []byte(`{"name": "John", "extra": 1}`)},
+		{jsonkit.ContentTypeProtobuf, binary},
+		{jsonkit.ContentTypeProtoText, []byte(`name: "John" extra: 1`)},
+	}
+
+	codec := jsonkit.NewCodec()
+	lenient := jsonkit.NewCodec()
+	lenient.DisallowUnknownFields = false
+
+	for _, c := range cases {
+		err := codec.BindProtoRequest(s.newRequest(c.contentType, c.body), &pb.User{})
+		var decodeErr *jsonkit.DecodeError
+		s.ErrorAs(err, &decodeErr, c.contentType)
+
+		msg := &pb.User{}
+		err = lenient.BindProtoRequest(s.newRequest(c.contentType, c.body), msg)
+		s.Nil(err, c.contentType)
+		s.True(proto.Equal(&pb.User{Name: "John"}, msg), c.contentType)
+	}
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_ExtraData() {
+	err := jsonkit.BindProtoRequest(s.newRequest(jsonkit.ContentTypeJSON, []byte(`{"name": "John"} {}`)), &pb.User{})
+
+	s.ErrorIs(err, jsonkit.ErrExtraData)
+}
+
+func (s *ProtoSuite) TestBindProtoRequest_BodyTooLarge() {
+	codec := jsonkit.NewCodec()
+	codec.MaxBodyBytes = 10
+	binary, err := proto.Marshal(&pb.User{Name: strings.Repeat("x", 20)})
+	s.Nil(err)
+
+	err = codec.BindProtoRequest(s.newRequest(jsonkit.ContentTypeProtobuf, binary), &pb.User{})
+
+	s.ErrorIs(err, jsonkit.ErrBodyTooLarge)
+}
+
+func (s *ProtoSuite) TestProtoResponse_Negotiation() {
+	cases := []struct {
+		accept   string
+		expected string
+	}{
+		{"", jsonkit.ContentTypeJSON},
+		{"*/*", jsonkit.ContentTypeJSON},
+		{"application/x-protobuf", jsonkit.ContentTypeProtobuf},
+		{"text/x-protobuf", jsonkit.ContentTypeProtoText},
+		{"application/json;q=0.5, application/x-protobuf", jsonkit.ContentTypeProtobuf},
+		{"application/x-protobuf;q=0.2, application/json", jsonkit.ContentTypeJSON},
+		{"application/x-protobuf;q=0, text/html", jsonkit.ContentTypeJSON},
+		{"text/html", jsonkit.ContentTypeJSON},
+	}
+
+	user := &pb.User{Name: "John", Age: 30}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		w := httptest.NewRecorder()
+
+		err := jsonkit.ProtoResponse(w, req, http.StatusOK, user)
+
+		s.Nil(err, c.accept)
+		s.Equal(c.expected, w.Header().Get("Content-Type"), c.accept)
+		s.Equal("Accept", w.Header().Get("Vary"))
+
+		decoded := &pb.User{}
+		req = s.newRequest(c.expected, w.Body.Bytes())
+		s.Nil(jsonkit.BindProtoRequest(req, decoded), c.accept)
+		s.True(proto.Equal(user, decoded), c.accept)
+	}
+}
+
+func TestProtoSuite(t *testing.T) {
+	suite.Run(t, new(ProtoSuite))
+}
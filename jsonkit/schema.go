@@ -0,0 +1,453 @@
+package jsonkit
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaDraft is the JSON Schema dialect produced by GenerateSchema.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema (draft 2020-12) document.
+// It covers the validation vocabulary jsonkit generates and checks;
+// unsupported keywords are dropped when decoding a schema.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+
+	Type  SchemaTypes   `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+
+	Items       *Schema `json:"items,omitempty"`
+	MinItems    *int    `json:"minItems,omitempty"`
+	MaxItems    *int    `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	MinLength       *int   `json:"minLength,omitempty"`
+	MaxLength       *int   `json:"maxLength,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+	Format          string `json:"format,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+}
+
+// SchemaTypes is the value of the type keyword, a single type or a list of types.
+type SchemaTypes []string
+
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaTypes{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// FalseSchema returns the schema rejecting every value, encoded as false.
+func FalseSchema() *Schema {
+	return &Schema{Not: &Schema{}}
+}
+
+func (s *Schema) isFalse() bool {
+	return reflect.DeepEqual(s, FalseSchema())
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.isFalse() {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = *FalseSchema()
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// SchemaProvider is implemented by types describing their own JSON Schema,
+// typically types with custom JSON marshaling.
+type SchemaProvider interface {
+	JSONSchema() *Schema
+}
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	rawMessageType     = reflect.TypeFor[json.RawMessage]()
+	schemaProviderType = reflect.TypeFor[SchemaProvider]()
+	textMarshalerType  = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// GenerateSchema generates the JSON Schema of the type of v from its json struct tags.
+// Named struct types are emitted once under $defs and referenced with $ref.
+// Fields without omitempty are required and structs don't allow additional properties.
+// This is stricter than UnMarshal, which leaves missing fields at their zero value,
+// so enabling Codec.ValidateSchema rejects bodies missing such fields.
+// The jsonschema struct tag adds constraints, e.g.
+// `jsonschema:"description=User age,minimum=0,maximum=150"` or `jsonschema:"enum=admin|member"`.
+func GenerateSchema(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("jsonkit: cannot generate schema for nil")
+	}
+	return SchemaFor(t)
+}
+
+// SchemaFor generates the JSON Schema of t, see GenerateSchema.
+func SchemaFor(t reflect.Type) (*Schema, error) {
+	g := &schemaGenerator{defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+	schema, err := g.schemaOf(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.Ref != "" {
+		// Keep the root self-contained: {"$ref": ..., "$defs": {...}}
+		schema = &Schema{Ref: schema.Ref}
+	}
+	schema.Schema = SchemaDraft
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+	return schema, nil
+}
+
+var schemaCache sync.Map // reflect.Type -> *Schema
+
+// cachedSchemaFor is SchemaFor memoized per type, used when Codec.ValidateSchema is set.
+func cachedSchemaFor(t reflect.Type) (*Schema, error) {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*Schema), nil
+	}
+
+	schema, err := SchemaFor(t)
+	if err != nil {
+		return nil, err
+	}
+	schemaCache.Store(t, schema)
+	return schema, nil
+}
+
+type schemaGenerator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) (*Schema, error) {
+	if t.Implements(schemaProviderType) {
+		if t.Kind() == reflect.Pointer {
+			return reflect.New(t.Elem()).Interface().(SchemaProvider).JSONSchema(), nil
+		}
+		return reflect.Zero(t).Interface().(SchemaProvider).JSONSchema(), nil
+	}
+	if reflect.PointerTo(t).Implements(schemaProviderType) {
+		return reflect.New(t).Interface().(SchemaProvider).JSONSchema(), nil
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "date-time"}, nil
+	case t == rawMessageType:
+		return &Schema{}, nil
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypes{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: SchemaTypes{"integer"}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: SchemaTypes{"integer"}, Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		schema, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(schema), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: SchemaTypes{"string"}, ContentEncoding: "base64"}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := &Schema{Type: SchemaTypes{"array"}, Items: items}
+		if t.Kind() == reflect.Array {
+			length := t.Len()
+			schema.MinItems, schema.MaxItems = &length, &length
+		} else {
+			schema = nullable(schema)
+		}
+		return schema, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return nil, fmt.Errorf("jsonkit: unsupported map key type %s", t.Key())
+			}
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(&Schema{Type: SchemaTypes{"object"}, AdditionalProperties: values}), nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.structRef(t)
+	default:
+		return nil, fmt.Errorf("jsonkit: unsupported type %s", t)
+	}
+}
+
+func (g *schemaGenerator) structRef(t reflect.Type) (*Schema, error) {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; g.defs[name] != nil; i++ {
+			name = t.Name() + strconv.Itoa(i)
+		}
+		g.names[t] = name
+		// Reserve the name before recursing so self references resolve
+		g.defs[name] = &Schema{}
+
+		schema, err := g.structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = schema
+	}
+	return &Schema{Ref: "#/$defs/" + escapeToken(name)}, nil
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{
+		Type:                 SchemaTypes{"object"},
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: FalseSchema(),
+	}
+	if err := g.addFields(schema, t); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Promote fields of embedded structs without a json name
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := g.addFields(schema, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		var fieldSchema *Schema
+		if hasTagOption(opts, "string") {
+			fieldSchema = &Schema{Type: SchemaTypes{"string"}}
+		} else {
+			var err error
+			if fieldSchema, err = g.schemaOf(field.Type); err != nil {
+				return fmt.Errorf("jsonkit: field %s.%s: %w", t.Name(), field.Name, err)
+			}
+		}
+
+		if constraints, ok := field.Tag.Lookup("jsonschema"); ok {
+			if fieldSchema.Ref != "" {
+				// Keep the shared definition untouched
+				fieldSchema = &Schema{AllOf: []*Schema{fieldSchema}}
+			}
+			if err := applySchemaTag(fieldSchema, constraints); err != nil {
+				return fmt.Errorf("jsonkit: field %s.%s: %w", t.Name(), field.Name, err)
+			}
+		}
+
+		schema.Properties[name] = fieldSchema
+		if !hasTagOption(opts, "omitempty") && !hasTagOption(opts, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var current string
+		current, opts, _ = strings.Cut(opts, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+func applySchemaTag(schema *Schema, tag string) error {
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(part, "=")
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "":
+		case "title":
+			schema.Title = value
+		case "description":
+			schema.Description = value
+		case "format":
+			schema.Format = value
+		case "pattern":
+			schema.Pattern = value
+		case "enum":
+			for _, option := range strings.Split(value, "|") {
+				schema.Enum = append(schema.Enum, enumValue(schema, option))
+			}
+		case "minimum":
+			schema.Minimum, err = parseFloatTag(value)
+		case "maximum":
+			schema.Maximum, err = parseFloatTag(value)
+		case "exclusiveMinimum":
+			schema.ExclusiveMinimum, err = parseFloatTag(value)
+		case "exclusiveMaximum":
+			schema.ExclusiveMaximum, err = parseFloatTag(value)
+		case "multipleOf":
+			schema.MultipleOf, err = parseFloatTag(value)
+		case "minLength":
+			schema.MinLength, err = parseIntTag(value)
+		case "maxLength":
+			schema.MaxLength, err = parseIntTag(value)
+		case "minItems":
+			schema.MinItems, err = parseIntTag(value)
+		case "maxItems":
+			schema.MaxItems, err = parseIntTag(value)
+		case "uniqueItems":
+			schema.UniqueItems = true
+		default:
+			return fmt.Errorf("unknown jsonschema tag key %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid jsonschema tag %q: %w", part, err)
+		}
+	}
+	return nil
+}
+
+// enumValue converts a tag enum option to the JSON type of the schema.
+func enumValue(schema *Schema, option string) interface{} {
+	for _, t := range schema.Type {
+		switch t {
+		case "integer", "number":
+			if _, err := strconv.ParseFloat(option, 64); err == nil {
+				return json.Number(option)
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(option); err == nil {
+				return b
+			}
+		}
+	}
+	return option
+}
+
+func parseFloatTag(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseIntTag(value string) (*int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// nullable allows null in addition to the values accepted by schema.
+func nullable(schema *Schema) *Schema {
+	switch {
+	case schema.Ref != "":
+		return &Schema{AnyOf: []*Schema{schema, {Type: SchemaTypes{"null"}}}}
+	case len(schema.Type) == 0 || schema.Type[len(schema.Type)-1] == "null":
+		return schema
+	default:
+		nullableSchema := *schema
+		nullableSchema.Type = append(append(SchemaTypes{}, schema.Type...), "null")
+		return &nullableSchema
+	}
+}
@@ -0,0 +1,246 @@
+package jsonkit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+)
+
+type schemaAddress struct {
+	City string `json:"city" jsonschema:"minLength=1"`
+	Zip  string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type schemaUser struct {
+	Name      string            `json:"name" jsonschema:"description=Display name,maxLength=10"`
+	Age       int               `json:"age" jsonschema:"minimum=0,maximum=150"`
+	Role      string            `json:"role" jsonschema:"enum=admin|member"`
+	Email     string            `json:"email,omitempty" jsonschema:"format=email"`
+	Tags      []string          `json:"tags,omitempty" jsonschema:"uniqueItems"`
+	Address   *schemaAddress    `json:"address,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Manager   *schemaUser       `json:"manager,omitempty"`
+	internal  string
+}
+
+type schemaColor int
+
+func (schemaColor) JSONSchema() *jsonkit.Schema {
+	return &jsonkit.Schema{Type: jsonkit.SchemaTypes{"string"}, Enum: []interface{}{"red", "green"}}
+}
+
+type SchemaSuite struct {
+	suite.Suite
+}
+
+func (s *SchemaSuite) TestGenerateSchema_Struct() {
+	schema, err := jsonkit.GenerateSchema(schemaUser{})
+	s.Nil(err)
+
+	s.Equal(jsonkit.SchemaDraft, schema.Schema)
+	s.Equal("#/$defs/schemaUser", schema.Ref)
+
+	user := schema.Defs["schemaUser"]
+	s.NotNil(user)
+	s.Equal(jsonkit.SchemaTypes{"object"}, user.Type)
+	s.ElementsMatch([]string{"name", "age", "role", "created_at"}, user.Required)
+	s.NotContains(user.Properties, "internal")
+	s.Equal("Display name", user.Properties["name"].Description)
+	s.Equal(10, *user.Properties["name"].MaxLength)
+	s.Equal(jsonkit.SchemaTypes{"integer"}, user.Properties["age"].Type)
+	s.Equal("date-time", user.Properties["created_at"].Format)
+	s.Equal(jsonkit.SchemaTypes{"array", "null"}, user.Properties["tags"].Type)
+	s.Len(user.Properties["manager"].AnyOf, 2)
+	s.Equal("#/$defs/schemaUser", user.Properties["manager"].AnyOf[0].Ref)
+	s.NotNil(schema.Defs["schemaAddress"])
+}
+
+func (s *SchemaSuite) TestGenerateSchema_MarshalJSON() {
+	schema, err := jsonkit.GenerateSchema(schemaAddress{})
+	s.Nil(err)
+
+	data, err := json.Marshal(schema)
+	s.Nil(err)
+	s.JSONEq(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/schemaAddress",
+		"$defs": {
+			"schemaAddress": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string", "minLength": 1},
+					"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+				},
+				"required": ["city"],
+				"additionalProperties": false
+			}
+		}
+	}`, string(data))
+
+	var decoded jsonkit.Schema
+	s.Nil(json.Unmarshal(data, &decoded))
+	s.Nil(decoded.ValidateJSON([]byte(`{"city":"Paris"}`)))
+	s.NotNil(decoded.ValidateJSON([]byte(`{"city":"Paris","extra":1}`)))
+}
+
+func (s *SchemaSuite) TestGenerateSchema_Provider() {
+	type paint struct {
+		Color schemaColor `json:"color"`
+	}
+
+	schema, err := jsonkit.GenerateSchema(paint{})
+	s.Nil(err)
+
+	s.Nil(schema.ValidateJSON([]byte(`{"color":"red"}`)))
+	s.NotNil(schema.ValidateJSON([]byte(`{"color":"blue"}`)))
+}
+
+func (s *SchemaSuite) TestGenerateSchema_Unsupported() {
+	_, err := jsonkit.GenerateSchema(struct {
+		C chan int `json:"c"`
+	}{})
+
+	s.NotNil(err)
+}
+
+func (s *SchemaSuite) TestValidate_Success() {
+	schema, err := jsonkit.GenerateSchema(schemaUser{})
+	s.Nil(err)
+
+	err = schema.ValidateJSON([]byte(`{
+		"name": "John",
+		"age": 30,
+		"role": "admin",
+		"email": "john@example.com",
+		"tags": ["a", "b"],
+		"address": {"city": "Paris", "zip": "75001"},
+		"labels": {"team": "core"},
+		"created_at": "2024-01-15T10:30:00Z",
+		"manager": {"name": "Jane", "age": 40, "role": "admin", "created_at": "2020-01-01T00:00:00Z", "manager": null}
+	}`))
+
+	s.Nil(err)
+}
+
+func (s *SchemaSuite) TestValidate_PathAnnotatedErrors() {
+	schema, err := jsonkit.GenerateSchema(schemaUser{})
+	s.Nil(err)
+
+	err = schema.ValidateJSON([]byte(`{
+		"name": "A very long name",
+		"age": -1,
+		"role": "owner",
+		"email": "not-an-email",
+		"tags": ["a", "a"],
+		"address": {"city": "", "zip": "abc"},
+		"labels": {"team": 1},
+		"created_at": "yesterday",
+		"manager": {"name": "Jane"},
+		"extra": true
+	}`))
+
+	var validationErrs jsonkit.ValidationErrors
+	s.True(errors.As(err, &validationErrs))
+
+	found := map[string]string{}
+	for _, e := range validationErrs {
+		found[e.Pointer.String()+" "+e.Keyword] = e.Message
+	}
+	for _, expected := range []string{
+		"/name maxLength",
+		"/age minimum",
+		"/role enum",
+		"/email format",
+		"/tags uniqueItems",
+		"/address/city minLength",
+		"/address/zip pattern",
+		"/labels/team type",
+		"/created_at format",
+		"/manager required",
+		"/extra additionalProperties",
+	} {
+		s.Contains(found, expected)
+	}
+}
+
+func (s *SchemaSuite) TestValidate_Keywords() {
+	two, five := 2, 5
+	multiple := 0.5
+	schema := &jsonkit.Schema{
+		Type: jsonkit.SchemaTypes{"object"},
+		Properties: map[string]*jsonkit.Schema{
+			"count":  {Type: jsonkit.SchemaTypes{"integer"}, MultipleOf: &multiple},
+			"items":  {Type: jsonkit.SchemaTypes{"array"}, MinItems: &two, MaxItems: &five, Items: &jsonkit.Schema{Type: jsonkit.SchemaTypes{"number"}}},
+			"kind":   {Const: "fixed"},
+			"choice": {OneOf: []*jsonkit.Schema{{Type: jsonkit.SchemaTypes{"string"}}, {Type: jsonkit.SchemaTypes{"integer"}}}},
+			"not":    {Not: &jsonkit.Schema{Type: jsonkit.SchemaTypes{"null"}}},
+		},
+	}
+
+	s.Nil(schema.ValidateJSON([]byte(`{"count": 2.0, "items": [1, 2.5], "kind": "fixed", "choice": 1, "not": 0}`)))
+
+	err := schema.ValidateJSON([]byte(`{"count": 1.5, "items": [1, "x"], "kind": "other", "choice": 1.5, "not": null}`))
+	var validationErrs jsonkit.ValidationErrors
+	s.True(errors.As(err, &validationErrs))
+	s.Len(validationErrs, 5)
+}
+
+func (s *SchemaSuite) TestValidate_Ref() {
+	schema := &jsonkit.Schema{
+		Defs: map[string]*jsonkit.Schema{
+			"node": {
+				Type: jsonkit.SchemaTypes{"object"},
+				Properties: map[string]*jsonkit.Schema{
+					"value":    {Type: jsonkit.SchemaTypes{"integer"}},
+					"children": {Type: jsonkit.SchemaTypes{"array"}, Items: &jsonkit.Schema{Ref: "#/$defs/node"}},
+				},
+			},
+		},
+		Ref: "#/$defs/node",
+	}
+
+	s.Nil(schema.ValidateJSON([]byte(`{"value": 1, "children": [{"value": 2, "children": []}]}`)))
+
+	err := schema.ValidateJSON([]byte(`{"value": 1, "children": [{"value": "x"}]}`))
+	var validationErrs jsonkit.ValidationErrors
+	s.True(errors.As(err, &validationErrs))
+	s.Equal("/children/0/value", validationErrs[0].Pointer.String())
+}
+
+func (s *SchemaSuite) TestValidate_InvalidJSON() {
+	schema := &jsonkit.Schema{}
+
+	err := schema.ValidateJSON([]byte(`{`))
+
+	s.NotNil(err)
+}
+
+func (s *SchemaSuite) TestBindRequestBody_ValidateSchema() {
+	codec := jsonkit.NewCodec()
+	codec.ValidateSchema = true
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"city": "Paris", "zip": "1"}`))
+	var address schemaAddress
+	err := codec.BindRequestBody(req, &address)
+
+	var validationErrs jsonkit.ValidationErrors
+	s.True(errors.As(err, &validationErrs))
+	s.Equal("/zip", validationErrs[0].Pointer.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"city": "Paris", "zip": "75001"}`))
+	err = codec.BindRequestBody(req, &address)
+	s.Nil(err)
+	s.Equal(schemaAddress{City: "Paris", Zip: "75001"}, address)
+}
+
+func TestSchemaSuite(t *testing.T) {
+	suite.Run(t, new(SchemaSuite))
+}
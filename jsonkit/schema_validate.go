@@ -0,0 +1,415 @@
+package jsonkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError reports a value violating a schema keyword.
+type ValidationError struct {
+	Pointer Pointer // location of the offending value in the document
+	Keyword string  // schema keyword that failed, e.g. "required" or "maxLength"
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Pointer) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+// ValidationErrors collects every violation found while validating a document.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "json schema validation failed: " + strings.Join(messages, "; ")
+}
+
+// ValidateJSON validates a raw JSON document against the schema.
+// It returns ValidationErrors when the document doesn't conform.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var doc interface{}
+	if err := documentCodec.UnMarshal(data, &doc); err != nil {
+		return err
+	}
+	return s.Validate(doc)
+}
+
+// Validate validates a decoded document against the schema.
+// It returns ValidationErrors when the document doesn't conform.
+func (s *Schema) Validate(doc interface{}) error {
+	v := &schemaValidator{root: s}
+	v.validate(s, normalizeDocument(doc), Pointer{})
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	root  *Schema
+	errs  ValidationErrors
+	depth int
+}
+
+// maxSchemaDepth guards against schemas whose $ref cycles never consume the document.
+const maxSchemaDepth = 512
+
+func (v *schemaValidator) fail(path Pointer, keyword, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Pointer: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// check validates doc against schema and returns the errors without recording them.
+func (v *schemaValidator) check(schema *Schema, doc interface{}, path Pointer) ValidationErrors {
+	sub := &schemaValidator{root: v.root, depth: v.depth}
+	sub.validate(schema, doc, path)
+	return sub.errs
+}
+
+func (v *schemaValidator) valid(schema *Schema, doc interface{}, path Pointer) bool {
+	return len(v.check(schema, doc, path)) == 0
+}
+
+func (v *schemaValidator) validate(schema *Schema, doc interface{}, path Pointer) {
+	if schema == nil {
+		return
+	}
+	if schema.isFalse() {
+		v.fail(path, "false", "value is not allowed")
+		return
+	}
+
+	v.depth++
+	defer func() { v.depth-- }()
+	if v.depth > maxSchemaDepth {
+		v.fail(path, "$ref", "schema nesting too deep")
+		return
+	}
+
+	if schema.Ref != "" {
+		target, err := v.resolveRef(schema.Ref)
+		if err != nil {
+			v.fail(path, "$ref", "%v", err)
+			return
+		}
+		v.validate(target, doc, path)
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return hasSchemaType(doc, t) }) {
+		v.fail(path, "type", "expected %s, got %s", strings.Join(schema.Type, " or "), schemaTypeOf(doc))
+		return
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e interface{}) bool { return jsonEqual(normalizeDocument(e), doc) }) {
+		v.fail(path, "enum", "value is not one of the allowed values")
+	}
+	if schema.Const != nil && !jsonEqual(normalizeDocument(schema.Const), doc) {
+		v.fail(path, "const", "value does not match the constant")
+	}
+
+	v.validateComposition(schema, doc, path)
+
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, value, path)
+	case []interface{}:
+		v.validateArray(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case json.Number:
+		if f, err := value.Float64(); err == nil {
+			v.validateNumber(schema, f, path)
+		}
+	}
+}
+
+func (v *schemaValidator) validateComposition(schema *Schema, doc interface{}, path Pointer) {
+	for _, sub := range schema.AllOf {
+		v.validate(sub, doc, path)
+	}
+
+	if len(schema.AnyOf) > 0 {
+		// When a single branch accepts the type of the value, e.g. the object branch of a
+		// nullable reference, its errors are more useful than a generic anyOf failure
+		var candidates []ValidationErrors
+		matched := false
+		for _, sub := range schema.AnyOf {
+			errs := v.check(sub, doc, path)
+			if len(errs) == 0 {
+				matched = true
+				break
+			}
+			if !slices.ContainsFunc(errs, func(e *ValidationError) bool { return e.Keyword == "type" && slices.Equal(e.Pointer, path) }) {
+				candidates = append(candidates, errs)
+			}
+		}
+
+		switch {
+		case matched:
+		case len(candidates) == 1:
+			v.errs = append(v.errs, candidates[0]...)
+		default:
+			v.fail(path, "anyOf", "value does not match any of the allowed schemas")
+		}
+	}
+
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, sub := range schema.OneOf {
+			if v.valid(sub, doc, path) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.fail(path, "oneOf", "value must match exactly one schema, matched %d", matches)
+		}
+	}
+
+	if schema.Not != nil && v.valid(schema.Not, doc, path) {
+		v.fail(path, "not", "value must not match the schema")
+	}
+}
+
+func (v *schemaValidator) validateObject(schema *Schema, obj map[string]interface{}, path Pointer) {
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			v.fail(path, "required", "missing required property %q", name)
+		}
+	}
+	if schema.MinProperties != nil && len(obj) < *schema.MinProperties {
+		v.fail(path, "minProperties", "must have at least %d properties", *schema.MinProperties)
+	}
+	if schema.MaxProperties != nil && len(obj) > *schema.MaxProperties {
+		v.fail(path, "maxProperties", "must have at most %d properties", *schema.MaxProperties)
+	}
+
+	for _, key := range sortedKeys(obj) {
+		if property, ok := schema.Properties[key]; ok {
+			v.validate(property, obj[key], path.Append(key))
+			continue
+		}
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+		if schema.AdditionalProperties.isFalse() {
+			v.fail(path.Append(key), "additionalProperties", "unknown property %q", key)
+			continue
+		}
+		v.validate(schema.AdditionalProperties, obj[key], path.Append(key))
+	}
+}
+
+func (v *schemaValidator) validateArray(schema *Schema, arr []interface{}, path Pointer) {
+	if schema.MinItems != nil && len(arr) < *schema.MinItems {
+		v.fail(path, "minItems", "must have at least %d items", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+		v.fail(path, "maxItems", "must have at most %d items", *schema.MaxItems)
+	}
+	if schema.UniqueItems {
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.fail(path, "uniqueItems", "items %d and %d are equal", j, i)
+				}
+			}
+		}
+	}
+
+	if schema.Items != nil {
+		for i, item := range arr {
+			v.validate(schema.Items, item, path.Append(fmt.Sprint(i)))
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema *Schema, s string, path Pointer) {
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(path, "minLength", "must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(path, "maxLength", "must be at most %d characters", *schema.MaxLength)
+	}
+
+	if schema.Pattern != "" {
+		re, err := compilePattern(schema.Pattern)
+		if err != nil {
+			v.fail(path, "pattern", "invalid pattern %q: %v", schema.Pattern, err)
+		} else if !re.MatchString(s) {
+			v.fail(path, "pattern", "does not match pattern %q", schema.Pattern)
+		}
+	}
+
+	if schema.Format != "" && !validFormat(schema.Format, s) {
+		v.fail(path, "format", "is not a valid %s", schema.Format)
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema *Schema, f float64, path Pointer) {
+	if schema.Minimum != nil && f < *schema.Minimum {
+		v.fail(path, "minimum", "must be >= %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && f > *schema.Maximum {
+		v.fail(path, "maximum", "must be <= %v", *schema.Maximum)
+	}
+	if schema.ExclusiveMinimum != nil && f <= *schema.ExclusiveMinimum {
+		v.fail(path, "exclusiveMinimum", "must be > %v", *schema.ExclusiveMinimum)
+	}
+	if schema.ExclusiveMaximum != nil && f >= *schema.ExclusiveMaximum {
+		v.fail(path, "exclusiveMaximum", "must be < %v", *schema.ExclusiveMaximum)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		quotient := f / *schema.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.fail(path, "multipleOf", "must be a multiple of %v", *schema.MultipleOf)
+		}
+	}
+}
+
+// resolveRef resolves local references of the form "#" or "#/json/pointer" against the root schema.
+func (v *schemaValidator) resolveRef(ref string) (*Schema, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q, only local references are resolved", ref)
+	}
+	unescaped, err := url.PathUnescape(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	tokens, err := ParsePointer(unescaped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+
+	schema := v.root
+	for i := 0; i < len(tokens); i++ {
+		var next *Schema
+		switch tokens[i] {
+		case "$defs", "definitions", "properties":
+			if i+1 == len(tokens) {
+				break
+			}
+			i++
+			if tokens[i-1] == "properties" {
+				next = schema.Properties[tokens[i]]
+			} else {
+				next = schema.Defs[tokens[i]]
+			}
+		case "items":
+			next = schema.Items
+		case "additionalProperties":
+			next = schema.AdditionalProperties
+		case "not":
+			next = schema.Not
+		}
+		if next == nil {
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+		schema = next
+	}
+	return schema, nil
+}
+
+var patternCache sync.Map // string -> *regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks the formats commonly used in API contracts, other formats are annotations only.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	default:
+		return true
+	}
+}
+
+func hasSchemaType(doc interface{}, t string) bool {
+	actual := schemaTypeOf(doc)
+	if actual == t {
+		return true
+	}
+	if t == "number" && actual == "integer" {
+		return true
+	}
+	return false
+}
+
+func schemaTypeOf(doc interface{}) string {
+	switch value := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if f, err := value.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+// normalizeDocument converts values built in Go, e.g. ints or structs in an enum,
+// to the decoded document form used by the validator.
+func normalizeDocument(doc interface{}) interface{} {
+	switch doc.(type) {
+	case nil, bool, string, json.Number:
+		return doc
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return doc
+	}
+	var normalized interface{}
+	if err := documentCodec.UnMarshal(data, &normalized); err != nil {
+		return doc
+	}
+	return normalized
+}
@@ -0,0 +1,212 @@
+package jsonkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentTypeEventStream is the media type of Server-Sent Events streams.
+const ContentTypeEventStream = "text/event-stream"
+
+// newTicker starts the ticks of a heartbeat, returning them with the function stopping them.
+// It is replaced in tests to drive heartbeats without waiting.
+var newTicker = func(interval time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// Event is a single Server-Sent Event.
+type Event struct {
+	Event string        // event type, the client dispatches "message" when empty
+	ID    string        // last event ID reported by the client when it reconnects
+	Data  interface{}   // marshaled with MarshalProto for proto.Message values and Marshal otherwise
+	Retry time.Duration // reconnection delay for the client, 0 to keep its current one
+}
+
+// SSEStream writes Server-Sent Events to a response, flushing after every event.
+// It is safe for concurrent use, so heartbeats can be sent while events are written.
+// Once the request context is cancelled every write fails with the context error.
+type SSEStream struct {
+	mu      sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+	ctx     context.Context
+
+	closed        bool
+	stopHeartbeat chan struct{}
+	heartbeatDone chan struct{}
+}
+
+// NewSSEStream sets the event stream headers, writes a 200 status
+// and returns a SSEStream bound to the request context.
+func NewSSEStream(w http.ResponseWriter, r *http.Request) *SSEStream {
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return &SSEStream{w: w, flusher: flusher, ctx: r.Context()}
+}
+
+// Send writes an event.
+func (s *SSEStream) Send(e Event) error {
+	if strings.ContainsAny(e.Event, "\r\n") || strings.ContainsAny(e.ID, "\r\n\x00") {
+		return fmt.Errorf("jsonkit: SSE event type and ID must be single line: %q, %q", e.Event, e.ID)
+	}
+
+	var data []byte
+	var err error
+	if msg, ok := e.Data.(proto.Message); ok {
+		data, err = MarshalProto(msg)
+	} else {
+		data, err = Marshal(e.Data)
+	}
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if e.Event != "" {
+		buf.WriteString("event: " + e.Event + "\n")
+	}
+	if e.ID != "" {
+		buf.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	// Indented JSON spans several lines, each one needs its own data field
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	return s.write(buf.Bytes())
+}
+
+// SendData writes an unnamed event carrying v.
+func (s *SSEStream) SendData(v interface{}) error {
+	return s.Send(Event{Data: v})
+}
+
+// Comment writes a comment line, ignored by clients but keeping the connection alive.
+func (s *SSEStream) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(": " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// Heartbeat sends a comment every interval until Close is called or the request context is done,
+// so proxies and load balancers don't close idle streams. Calling it again replaces the previous heartbeat,
+// and an interval <= 0 stops it. The heartbeat writes from its own goroutine, so Close must be called
+// before the handler returns: the ResponseWriter must not be used once ServeHTTP has returned.
+func (s *SSEStream) Heartbeat(interval time.Duration) {
+	s.stop()
+	if interval <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.stopHeartbeat, s.heartbeatDone = stop, done
+
+	go func() {
+		defer close(done)
+		ticks, stopTicker := newTicker(interval)
+		defer stopTicker()
+
+		for {
+			select {
+			case <-ticks:
+				if err := s.Comment("heartbeat"); err != nil {
+					return
+				}
+			case <-stop:
+				return
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the heartbeat and waits for it to exit, then ends the stream:
+// later writes fail with ErrStreamClosed and Heartbeat does nothing.
+// The response itself ends when the handler returns.
+func (s *SSEStream) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.stop()
+}
+
+// stop stops the heartbeat, if any, and waits for its goroutine to exit.
+func (s *SSEStream) stop() {
+	s.mu.Lock()
+	stop, done := s.stopHeartbeat, s.heartbeatDone
+	s.stopHeartbeat, s.heartbeatDone = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Done is closed when the client goes away.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+func (s *SSEStream) write(frame []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(frame); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// WriteSSE sends every value of seq as an unnamed event,
+// stopping at the first error or when the request context is cancelled.
+func WriteSSE[T any](s *SSEStream, seq iter.Seq[T]) error {
+	for v := range seq {
+		if err := s.SendData(v); err != nil {
+			return err
+		}
+	}
+	return s.ctx.Err()
+}
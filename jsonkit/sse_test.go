@@ -0,0 +1,158 @@
+package jsonkit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/jsonkit"
+	pb "github.com/umefy/godash/jsonkit/testdata"
+)
+
+type SSESuite struct {
+	suite.Suite
+}
+
+func (s *SSESuite) newStream(ctx context.Context) (*jsonkit.SSEStream, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	return jsonkit.NewSSEStream(w, req), w
+}
+
+func (s *SSESuite) TestNewSSEStream_Headers() {
+	_, w := s.newStream(context.Background())
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(jsonkit.ContentTypeEventStream, w.Header().Get("Content-Type"))
+	s.Equal("no-cache", w.Header().Get("Cache-Control"))
+	s.True(w.Flushed)
+}
+
+func (s *SSESuite) TestSend_Frames() {
+	stream, w := s.newStream(context.Background())
+
+	s.Nil(stream.Send(jsonkit.Event{Event: "progress", ID: "1", Data: map[string]int{"percent": 50}, Retry: 3 * time.Second}))
+	s.Nil(stream.SendData(&pb.User{Name: "John", Age: 30}))
+	s.Nil(stream.Comment("keep alive"))
+
+	frames := strings.Split(w.Body.String(), "\n\n")
+	s.Len(frames, 4)
+	s.Equal("event: progress\nid: 1\nretry: 3000\ndata: {\"percent\":50}", frames[0])
+	data, ok := strings.CutPrefix(frames[1], "data: ")
+	s.True(ok)
+	s.JSONEq(`{"name": "John", "city": "", "age": 30}`, data)
+	s.Equal(": keep alive", frames[2])
+	s.Empty(frames[3])
+}
+
+func (s *SSESuite) TestSend_InvalidFields() {
+	stream, w := s.newStream(context.Background())
+
+	s.NotNil(stream.Send(jsonkit.Event{Event: "a\nb"}))
+	s.NotNil(stream.Send(jsonkit.Event{ID: "1\r"}))
+	s.Empty(w.Body.String())
+}
+
+func (s *SSESuite) TestSend_ContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, w := s.newStream(ctx)
+
+	s.Nil(stream.SendData(1))
+	cancel()
+
+	s.ErrorIs(stream.SendData(2), context.Canceled)
+	s.Equal("data: 1\n\n", w.Body.String())
+	<-stream.Done()
+}
+
+// tick reports whether a running heartbeat received a tick.
+func (s *SSESuite) tick(ticks chan<- time.Time) bool {
+	select {
+	case ticks <- time.Now():
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+// noTick reports whether no heartbeat is left to receive a tick.
+func (s *SSESuite) noTick(ticks chan<- time.Time) bool {
+	select {
+	case ticks <- time.Now():
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *SSESuite) TestHeartbeat() {
+	ticks := make(chan time.Time)
+	defer jsonkit.SetTicker(ticks)()
+	stream, w := s.newStream(context.Background())
+
+	stream.Heartbeat(time.Minute)
+	s.True(s.tick(ticks))
+	// The heartbeat takes the second tick once it has written the first comment
+	s.True(s.tick(ticks))
+	stream.Close()
+
+	s.True(strings.HasPrefix(w.Body.String(), ": heartbeat\n\n"), w.Body.String())
+	// Close waits for the heartbeat to exit
+	s.True(s.noTick(ticks))
+}
+
+func (s *SSESuite) TestHeartbeat_StopsOnCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, _ := s.newStream(ctx)
+
+	stream.Heartbeat(time.Millisecond)
+	cancel()
+
+	// Close waits for the heartbeat goroutine, which exits on its own after the cancellation
+	stream.Close()
+}
+
+func (s *SSESuite) TestHeartbeat_DisabledInterval() {
+	ticks := make(chan time.Time)
+	defer jsonkit.SetTicker(ticks)()
+	stream, w := s.newStream(context.Background())
+
+	stream.Heartbeat(time.Minute)
+	stream.Heartbeat(0) // stops the previous heartbeat instead of panicking
+
+	s.True(s.noTick(ticks))
+	s.Empty(w.Body.String())
+	stream.Close()
+}
+
+func (s *SSESuite) TestClose_EndsStream() {
+	ticks := make(chan time.Time)
+	defer jsonkit.SetTicker(ticks)()
+	stream, w := s.newStream(context.Background())
+
+	s.Nil(stream.SendData(1))
+	stream.Close()
+	stream.Heartbeat(time.Minute)
+
+	s.True(s.noTick(ticks))
+	s.ErrorIs(stream.SendData(2), jsonkit.ErrStreamClosed)
+	s.Equal("data: 1\n\n", w.Body.String())
+}
+
+func (s *SSESuite) TestWriteSSE() {
+	stream, w := s.newStream(context.Background())
+
+	err := jsonkit.WriteSSE(stream, slices.Values([]string{"a", "b"}))
+
+	s.Nil(err)
+	s.Equal("data: \"a\"\n\ndata: \"b\"\n\n", w.Body.String())
+}
+
+func TestSSESuite(t *testing.T) {
+	suite.Run(t, new(SSESuite))
+}
@@ -0,0 +1,280 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DroppedKey is the attribute key of the number of records dropped by a full async buffer.
+const DroppedKey = "dropped"
+
+// OverflowPolicy decides what happens to records logged while the async buffer is full.
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // wait for room in the buffer
+	OverflowDropNewest                       // drop the record being logged
+	OverflowDropOldest                       // drop the oldest buffered record to make room
+)
+
+// AsyncOps configures asynchronous logging: records are enriched in the calling goroutine,
+// then buffered and written by a background goroutine, so slow writers don't add latency to callers.
+// Dropped records are reported by a warning record with the DroppedKey count.
+type AsyncOps struct {
+	BufferSize int            // records buffered, 1024 when zero
+	Overflow   OverflowPolicy // OverflowBlock by default
+	BatchSize  int            // records written to the Writer in one call, 128 when zero
+}
+
+// NewAsyncOps creates async options buffering bufferSize records with the given overflow policy.
+func NewAsyncOps(bufferSize int, overflow OverflowPolicy) *AsyncOps {
+	return &AsyncOps{BufferSize: bufferSize, Overflow: overflow, BatchSize: 128}
+}
+
+var errQueueClosed = errors.New("logger: async queue closed")
+
+type asyncEntry struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// asyncQueue is a ring buffer of records written by a single worker goroutine.
+type asyncQueue struct {
+	overflow  OverflowPolicy
+	batchSize int
+	writer    *batchWriter
+	// handler reports dropped records
+	handler slog.Handler
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signaled when entries are added, removed or written
+	ring     []asyncEntry
+	head     int
+	size     int
+	dropped  int
+	enqueued uint64 // entries accepted since the start
+	written  uint64 // entries written or dropped since the start
+	closed   bool
+	done     chan struct{}
+}
+
+func newAsyncQueue(opts *AsyncOps, writer *batchWriter, handler slog.Handler) *asyncQueue {
+	bufferSize, batchSize := opts.BufferSize, opts.BatchSize
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	if batchSize <= 0 {
+		batchSize = 128
+	}
+
+	q := &asyncQueue{
+		overflow:  opts.Overflow,
+		batchSize: batchSize,
+		writer:    writer,
+		handler:   handler,
+		ring:      make([]asyncEntry, bufferSize),
+		done:      make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// push buffers an entry, failing once the queue is closed.
+func (q *asyncQueue) push(e asyncEntry) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && q.size == len(q.ring) {
+		switch q.overflow {
+		case OverflowDropNewest:
+			q.dropped++
+			return nil
+		case OverflowDropOldest:
+			q.ring[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.ring)
+			q.size--
+			q.dropped++
+			q.written++
+		default:
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		return errQueueClosed
+	}
+
+	q.ring[(q.head+q.size)%len(q.ring)] = e
+	q.size++
+	q.enqueued++
+	q.cond.Broadcast()
+	return nil
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	batch := make([]asyncEntry, 0, q.batchSize)
+	for {
+		q.mu.Lock()
+		for q.size == 0 && q.dropped == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.size == 0 && q.dropped == 0 && q.closed {
+			q.mu.Unlock()
+			return
+		}
+		for len(batch) < q.batchSize && q.size > 0 {
+			batch = append(batch, q.ring[q.head])
+			q.ring[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.ring)
+			q.size--
+		}
+		dropped := q.dropped
+		q.dropped = 0
+		// Room was made for blocked callers
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		if dropped > 0 {
+			r := slog.NewRecord(time.Now(), slog.LevelWarn, "logger dropped records", 0)
+			r.AddAttrs(slog.Int(DroppedKey, dropped))
+			_ = q.handler.Handle(context.Background(), r)
+		}
+		for _, e := range batch {
+			_ = e.handler.Handle(e.ctx, e.record)
+		}
+		_ = q.writer.flush()
+
+		q.mu.Lock()
+		q.written += uint64(len(batch))
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		clear(batch)
+		batch = batch[:0]
+	}
+}
+
+// flush waits until the entries buffered before the call are written.
+func (q *asyncQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+
+	target := q.enqueued
+	for q.written < target {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// close stops accepting entries, then waits for the buffered ones to be written.
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// asyncHandler hands records over to the queue, the wrapped handler writing them from the worker.
+type asyncHandler struct {
+	handler slog.Handler
+	queue   *asyncQueue
+}
+
+var _ slog.Handler = (*asyncHandler)(nil)
+
+func (h *asyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *asyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	// The record may be written after the caller returns and its context is cancelled
+	err := h.queue.push(asyncEntry{ctx: context.WithoutCancel(ctx), handler: h.handler, record: r.Clone()})
+	if errors.Is(err, errQueueClosed) {
+		// Records logged after Close are written synchronously rather than lost
+		if err := h.handler.Handle(ctx, r); err != nil {
+			return err
+		}
+		return h.queue.writer.flush()
+	}
+	return err
+}
+
+func (h *asyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &asyncHandler{handler: h.handler.WithAttrs(attrs), queue: h.queue}
+}
+
+func (h *asyncHandler) WithGroup(name string) slog.Handler {
+	return &asyncHandler{handler: h.handler.WithGroup(name), queue: h.queue}
+}
+
+// batchWriter buffers the records of a batch to write them to the Writer in one call.
+type batchWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *batchWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// Flush waits until the records logged before the call are written, or ctx is done.
+// It returns immediately for synchronous loggers.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.async == nil {
+		return nil
+	}
+	return l.async.flush(ctx)
+}
+
+// Close writes the records collapsed by deduplication, then the buffered records of async loggers,
+// waiting until they are written or ctx is done. Records logged after Close are written synchronously.
+// The Writer is left open.
+func (l *Logger) Close(ctx context.Context) error {
+	if l.handler.deduper != nil {
+		l.handler.deduper.flushAll()
+	}
+	if l.async == nil {
+		return nil
+	}
+	return l.async.close(ctx)
+}
@@ -0,0 +1,236 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+// gatedWriter blocks writes until it is opened, counting the write calls.
+type gatedWriter struct {
+	syncBuffer
+	gate    chan struct{}
+	entered chan struct{} // signaled when a write starts waiting on the gate
+	mu      sync.Mutex
+	writes  int
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.gate
+	w.mu.Lock()
+	w.writes++
+	w.mu.Unlock()
+	return w.syncBuffer.Write(p)
+}
+
+func (w *gatedWriter) open() {
+	close(w.gate)
+}
+
+func (w *gatedWriter) writeCalls() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+type AsyncSuite struct {
+	suite.Suite
+}
+
+func (s *AsyncSuite) newLogger(w *gatedWriter, async *logger.AsyncOps) *logger.Logger {
+	opts := logger.NewLoggerOps(true, w, slog.LevelInfo, false, "", 0)
+	opts.Async = async
+	return logger.New(opts, nil)
+}
+
+func (s *AsyncSuite) messages(w *gatedWriter) []string {
+	var messages []string
+	for _, record := range w.records() {
+		messages = append(messages, record["msg"].(string))
+	}
+	return messages
+}
+
+func (s *AsyncSuite) TestDoesNotBlockCaller() {
+	w := newGatedWriter()
+	log := s.newLogger(w, logger.NewAsyncOps(16, logger.OverflowBlock))
+	ctx, cancel := context.WithCancel(log.WithValue(context.Background(), slog.String("request_id", "r1")))
+
+	log.InfoContext(ctx, "first", "n", 1)
+	log.WithGroup("g").InfoContext(ctx, "second", "n", 2)
+	cancel()
+	s.Empty(w.records())
+
+	w.open()
+	s.Nil(log.Flush(context.Background()))
+
+	records := w.records()
+	s.Len(records, 2)
+	s.Equal("r1", records[0]["request_id"])
+	s.Equal(map[string]any{"n": float64(2)}, records[1]["g"])
+	s.Nil(log.Close(context.Background()))
+}
+
+func (s *AsyncSuite) TestBatching() {
+	w := newGatedWriter()
+	log := s.newLogger(w, &logger.AsyncOps{BufferSize: 64, BatchSize: 64})
+
+	log.Info("blocked") // taken by the worker, which then waits on the writer
+	<-w.entered
+	for i := 0; i < 20; i++ {
+		log.Info("batched")
+	}
+	w.open()
+	s.Nil(log.Close(context.Background()))
+
+	s.Len(w.records(), 21)
+	s.Equal(2, w.writeCalls())
+}
+
+func (s *AsyncSuite) TestOverflowDropNewest() {
+	w := newGatedWriter()
+	log := s.newLogger(w, &logger.AsyncOps{BufferSize: 2, Overflow: logger.OverflowDropNewest, BatchSize: 1})
+
+	log.Info("in flight")
+	<-w.entered
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		log.Info(msg)
+	}
+	w.open()
+	s.Nil(log.Close(context.Background()))
+
+	// Drops are reported with the next batch
+	s.Equal([]string{"in flight", "logger dropped records", "a", "b"}, s.messages(w))
+	s.Equal(float64(2), w.records()[1][logger.DroppedKey])
+}
+
+func (s *AsyncSuite) TestOverflowDropOldest() {
+	w := newGatedWriter()
+	log := s.newLogger(w, &logger.AsyncOps{BufferSize: 2, Overflow: logger.OverflowDropOldest, BatchSize: 1})
+
+	log.Info("in flight")
+	<-w.entered
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		log.Info(msg)
+	}
+	w.open()
+	s.Nil(log.Close(context.Background()))
+
+	s.Equal([]string{"in flight", "logger dropped records", "c", "d"}, s.messages(w))
+	s.Equal(float64(2), w.records()[1][logger.DroppedKey])
+}
+
+func (s *AsyncSuite) TestOverflowBlock() {
+	w := newGatedWriter()
+	log := s.newLogger(w, &logger.AsyncOps{BufferSize: 1, BatchSize: 1})
+
+	log.Info("in flight")
+	<-w.entered
+	log.Info("buffered")
+
+	logged := make(chan struct{})
+	go func() {
+		log.Info("blocked")
+		close(logged)
+	}()
+	select {
+	case <-logged:
+		s.Fail("logging should block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	w.open()
+	<-logged
+	s.Nil(log.Close(context.Background()))
+	s.Equal([]string{"in flight", "buffered", "blocked"}, s.messages(w))
+}
+
+func (s *AsyncSuite) TestFlushTimeout() {
+	w := newGatedWriter()
+	log := s.newLogger(w, logger.NewAsyncOps(16, logger.OverflowBlock))
+
+	log.Info("stuck")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	s.ErrorIs(log.Flush(ctx), context.DeadlineExceeded)
+	s.ErrorIs(log.Close(ctx), context.DeadlineExceeded)
+
+	w.open()
+	s.Nil(log.Flush(context.Background()))
+	s.Len(w.records(), 1)
+}
+
+func (s *AsyncSuite) TestAfterClose() {
+	w := newGatedWriter()
+	w.open()
+	log := s.newLogger(w, logger.NewAsyncOps(16, logger.OverflowBlock))
+
+	s.Nil(log.Close(context.Background()))
+	log.Named("db").Info("after close")
+
+	records := w.records()
+	s.Len(records, 1)
+	s.Equal("db", records[0][logger.ComponentKey])
+}
+
+func (s *AsyncSuite) TestCloseFlushesDuplicates() {
+	w := newGatedWriter()
+	w.open()
+	opts := logger.NewLoggerOps(true, w, slog.LevelInfo, false, "", 0)
+	opts.Sampling = &logger.SamplingOps{Interval: time.Hour, Dedupe: true}
+	log := logger.New(opts, nil)
+
+	log.Info("repeated")
+	log.Info("repeated")
+	log.Info("repeated")
+	s.Nil(log.Close(context.Background()))
+
+	records := w.records()
+	s.Len(records, 2)
+	s.Equal(float64(2), records[1][logger.RepeatedKey])
+}
+
+func (s *AsyncSuite) TestConcurrent() {
+	w := newGatedWriter()
+	w.open()
+	log := s.newLogger(w, &logger.AsyncOps{BufferSize: 8, BatchSize: 4})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info("concurrent", "worker", i)
+			}
+		}()
+	}
+	wg.Wait()
+	s.Nil(log.Close(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(w.buf.String()), "\n")
+	s.Len(lines, 800)
+	for _, line := range lines {
+		s.True(json.Valid([]byte(line)), line)
+	}
+}
+
+func TestAsyncSuite(t *testing.T) {
+	suite.Run(t, new(AsyncSuite))
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const (
+	// ComponentKey is the attribute key of the component name of named loggers.
+	ComponentKey = "component"
+	// DefaultComponent sets the level of loggers without a more specific entry in component levels.
+	DefaultComponent = "*"
+)
+
+// ParseComponentLevels parses component levels such as "db=debug,http=warn,*=info",
+// typically read from an environment variable. A bare level, such as "debug", sets the default level.
+func ParseComponentLevels(s string) (map[string]slog.Level, error) {
+	levels := map[string]slog.Level{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		component, text, ok := strings.Cut(entry, "=")
+		if !ok {
+			component, text = DefaultComponent, entry
+		}
+		component = strings.TrimSpace(component)
+		if component == "" {
+			return nil, fmt.Errorf("logger: missing component name in %q", entry)
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(text))); err != nil {
+			return nil, fmt.Errorf("logger: invalid level of component %q: %w", component, err)
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+// Named returns a logger for a component, emitting its name as the ComponentKey attribute
+// and filtering records with the component level. Names of nested loggers are joined with
+// dots, "db" and "pool" giving "db.pool", which falls back to the level of "db" without entry of its own.
+func (l *Logger) Named(name string) *Logger {
+	if l.handler.component != "" {
+		name = l.handler.component + "." + name
+	}
+
+	h := *l.handler
+	h.component = name
+	h.base = h.root.WithAttrs([]slog.Attr{slog.String(ComponentKey, name)})
+	h.Handler = h.replay(h.base)
+	return &Logger{
+		Logger:  slog.New(&h),
+		handler: &h,
+		opts:    l.opts,
+		level:   l.level,
+		async:   l.async,
+	}
+}
+
+// Component returns the component name of a named logger.
+func (l *Logger) Component() string {
+	return l.handler.component
+}
+
+// componentLevel returns the minimum level of a component, the most specific entry
+// of its dotted name or the logger level without entry.
+func (c *levelControl) componentLevel(component string) slog.Level {
+	for component != "" {
+		if level, ok := c.components[component]; ok {
+			return level
+		}
+		i := strings.LastIndex(component, ".")
+		if i < 0 {
+			break
+		}
+		component = component[:i]
+	}
+	return c.level.Level()
+}
+
+// Level implements slog.Leveler for the wrapped handler, with the lowest level of
+// all components so it lets through every record the component levels enable.
+func (c *levelControl) Level() slog.Level {
+	level := c.level.Level()
+	for component, l := range c.components {
+		if component != DefaultComponent {
+			level = min(level, l)
+		}
+	}
+	return level
+}
@@ -0,0 +1,102 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+type ComponentSuite struct {
+	suite.Suite
+	buf *bytes.Buffer
+	log *logger.Logger
+}
+
+func (s *ComponentSuite) SetupTest() {
+	levels, err := logger.ParseComponentLevels("db=debug, http=warn, *=info")
+	s.Require().Nil(err)
+
+	s.buf = &bytes.Buffer{}
+	opts := logger.NewLoggerOps(true, s.buf, slog.LevelError, false, "", 0)
+	opts.ComponentLevels = levels
+	s.log = logger.New(opts, nil)
+}
+
+func (s *ComponentSuite) messages() map[string]string {
+	messages := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(s.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		s.Require().Nil(json.Unmarshal([]byte(line), &record))
+		component, _ := record[logger.ComponentKey].(string)
+		messages[record["msg"].(string)] = component
+	}
+	return messages
+}
+
+func (s *ComponentSuite) TestParseComponentLevels() {
+	levels, err := logger.ParseComponentLevels(" db=DEBUG,http=warn+2 ,,debug")
+
+	s.Nil(err)
+	s.Equal(map[string]slog.Level{"db": slog.LevelDebug, "http": slog.LevelWarn + 2, "*": slog.LevelDebug}, levels)
+
+	for _, c := range []string{"db=verbose", "=debug", "db="} {
+		_, err := logger.ParseComponentLevels(c)
+
+		s.NotNil(err, c)
+	}
+}
+
+func (s *ComponentSuite) TestNamed() {
+	db := s.log.Named("db")
+	pool := db.Named("pool")
+	http := s.log.Named("http")
+
+	s.log.Debug("root debug")
+	s.log.Info("root info")
+	db.Debug("db debug")
+	pool.With("size", 10).Debug("pool debug")
+	http.Info("http info")
+	http.Warn("http warn")
+
+	s.Equal("db.pool", pool.Component())
+	s.Equal(map[string]string{
+		"root info":  "",
+		"db debug":   "db",
+		"pool debug": "db.pool",
+		"http warn":  "http",
+	}, s.messages())
+}
+
+func (s *ComponentSuite) TestNamed_DefaultLevel() {
+	cache := s.log.Named("cache")
+
+	cache.Debug("hidden")
+	s.log.SetLevel(slog.LevelDebug)
+	cache.Debug("shown")
+
+	// Component entries are not affected by the logger level
+	s.log.Named("http").Info("still hidden")
+
+	s.Equal(map[string]string{"shown": "cache"}, s.messages())
+}
+
+func (s *ComponentSuite) TestNamed_ComponentAtTopLevel() {
+	s.log.Named("db").WithGroup("query").Debug("grouped", "table", "users")
+
+	var record map[string]any
+	s.Require().Nil(json.Unmarshal(s.buf.Bytes(), &record))
+	s.Equal("db", record[logger.ComponentKey])
+	s.Equal(map[string]any{"table": "users"}, record["query"])
+}
+
+func TestComponentSuite(t *testing.T) {
+	suite.Run(t, new(ComponentSuite))
+}
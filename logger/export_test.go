@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"os"
+	"time"
+)
+
+// PackagePath exposes packagePath to the external test package.
+var PackagePath = packagePath
+
+// SetRename replaces the function moving rotated files until the returned restore is called.
+func SetRename(fn func(oldpath, newpath string) error) (restore func()) {
+	rename = fn
+	return func() { rename = os.Rename }
+}
+
+// SetNow replaces the clock of rotations until the returned restore is called.
+func SetNow(fn func() time.Time) (restore func()) {
+	now = fn
+	return func() { now = time.Now }
+}
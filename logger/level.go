@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// levelControl holds the runtime level of a logger, shared by the loggers derived from it,
+// and the fixed levels of named components.
+type levelControl struct {
+	level      *slog.LevelVar
+	components map[string]slog.Level
+
+	mu       sync.Mutex
+	revert   *time.Timer
+	revertTo slog.Level
+	revertAt time.Time
+}
+
+func newLevelControl(opts *LoggerOps) *levelControl {
+	c := &levelControl{components: maps.Clone(opts.ComponentLevels)}
+	if opts.HandlerOptions != nil {
+		c.level, _ = opts.HandlerOptions.Level.(*slog.LevelVar)
+	}
+	if c.level == nil {
+		level := opts.Level
+		if opts.HandlerOptions != nil && opts.HandlerOptions.Level != nil {
+			level = opts.HandlerOptions.Level.Level()
+		}
+		c.level = &slog.LevelVar{}
+		c.level.Set(level)
+	}
+
+	if level, ok := c.components[DefaultComponent]; ok {
+		c.level.Set(level)
+	}
+	return c
+}
+
+// set changes the level, going back to the previous one after revertAfter when it is positive.
+// Setting the level again replaces a pending revert, keeping the level it goes back to.
+func (c *levelControl) set(level slog.Level, revertAfter time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	revertTo := c.level.Level()
+	if c.revert != nil {
+		c.revert.Stop()
+		revertTo = c.revertTo
+	}
+	c.revert, c.revertAt = nil, time.Time{}
+	c.level.Set(level)
+	if revertAfter <= 0 {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(revertAfter, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// A timer stopped too late must not undo a later change
+		if c.revert == timer {
+			c.level.Set(c.revertTo)
+			c.revert, c.revertAt = nil, time.Time{}
+		}
+	})
+	c.revert, c.revertTo, c.revertAt = timer, revertTo, time.Now().Add(revertAfter)
+}
+
+// state returns the current level and when it reverts, zero without pending revert.
+func (c *levelControl) state() (slog.Level, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level.Level(), c.revertAt
+}
+
+// Level returns the current minimum level.
+func (l *Logger) Level() slog.Level {
+	return l.level.level.Level()
+}
+
+// SetLevel changes the minimum level of the logger and of the loggers derived from it,
+// cancelling any pending revert of the level handler.
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.set(level, 0)
+}
+
+// SetLevelFor changes the minimum level for the given duration, then reverts to the current one.
+func (l *Logger) SetLevelFor(level slog.Level, d time.Duration) {
+	l.level.set(level, d)
+}
+
+// LevelVar returns the variable holding the level, to share it with other handlers.
+func (l *Logger) LevelVar() *slog.LevelVar {
+	return l.level.level
+}
+
+// levelState is the body of level handler requests and responses.
+type levelState struct {
+	Level *slog.Level `json:"level"` // required in requests
+	// RevertAfter is a duration such as "15m" in requests.
+	RevertAfter string     `json:"revert_after,omitempty"`
+	RevertAt    *time.Time `json:"revert_at,omitempty"`
+}
+
+// LevelHandler returns an http.Handler reading the level on GET and changing it on PUT or POST,
+// with a JSON body such as {"level": "debug", "revert_after": "15m"} or the level and revert_after query parameters.
+// With revert_after the previous level is restored after the duration, so debug logging
+// turned on during an incident doesn't stay on. Mount it on an internal admin listener.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			level, revertAfter, err := parseLevelRequest(w, r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			l.level.set(level, revertAfter)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		level, revertAt := l.level.state()
+		state := levelState{Level: &level}
+		if !revertAt.IsZero() {
+			state.RevertAt = &revertAt
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+}
+
+func parseLevelRequest(w http.ResponseWriter, r *http.Request) (slog.Level, time.Duration, error) {
+	var state levelState
+	query := r.URL.Query()
+	if query.Has("level") {
+		state.Level = new(slog.Level)
+		if err := state.Level.UnmarshalText([]byte(query.Get("level"))); err != nil {
+			return 0, 0, err
+		}
+		state.RevertAfter = query.Get("revert_after")
+	} else {
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&state); err != nil {
+			return 0, 0, fmt.Errorf("logger: invalid level request: %w", err)
+		}
+		if state.Level == nil {
+			return 0, 0, errors.New("logger: invalid level request: missing level")
+		}
+	}
+
+	if state.RevertAfter == "" {
+		return *state.Level, 0, nil
+	}
+	revertAfter, err := time.ParseDuration(state.RevertAfter)
+	if err != nil || revertAfter <= 0 {
+		return 0, 0, fmt.Errorf("logger: invalid revert_after %q", state.RevertAfter)
+	}
+	return *state.Level, revertAfter, nil
+}
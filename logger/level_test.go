@@ -0,0 +1,135 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+type LevelSuite struct {
+	suite.Suite
+	buf *bytes.Buffer
+	log *logger.Logger
+}
+
+func (s *LevelSuite) SetupTest() {
+	s.buf = &bytes.Buffer{}
+	s.log = logger.New(logger.NewLoggerOps(true, s.buf, slog.LevelInfo, false, "", 0), nil)
+}
+
+func (s *LevelSuite) serve(method, target, body string) (*httptest.ResponseRecorder, map[string]any) {
+	w := httptest.NewRecorder()
+	s.log.LevelHandler().ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	var state map[string]any
+	if w.Code == http.StatusOK {
+		s.Require().Nil(json.Unmarshal(w.Body.Bytes(), &state))
+	}
+	return w, state
+}
+
+func (s *LevelSuite) TestSetLevel() {
+	derived := s.log.With("component", "db")
+
+	derived.Debug("hidden")
+	s.log.SetLevel(slog.LevelDebug)
+	derived.Debug("shown")
+
+	s.Equal(slog.LevelDebug, s.log.Level())
+	s.NotContains(s.buf.String(), "hidden")
+	s.Contains(s.buf.String(), "shown")
+}
+
+func (s *LevelSuite) TestLevelVarOption() {
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	opts := logger.NewLoggerOps(true, s.buf, slog.LevelInfo, false, "", 0)
+	opts.HandlerOptions.Level = &level
+
+	log := logger.New(opts, nil)
+	log.Info("hidden")
+	level.Set(slog.LevelInfo)
+	log.Info("shown")
+
+	s.Same(&level, log.LevelVar())
+	s.NotContains(s.buf.String(), "hidden")
+	s.Contains(s.buf.String(), "shown")
+}
+
+func (s *LevelSuite) TestSetLevelFor() {
+	s.log.SetLevelFor(slog.LevelDebug, 20*time.Millisecond)
+	s.log.SetLevelFor(slog.LevelWarn, 20*time.Millisecond)
+	s.Equal(slog.LevelWarn, s.log.Level())
+
+	// Reverts to the level set before the first temporary change
+	s.Eventually(func() bool { return s.log.Level() == slog.LevelInfo }, time.Second, 5*time.Millisecond)
+
+	s.log.SetLevelFor(slog.LevelDebug, 10*time.Millisecond)
+	s.log.SetLevel(slog.LevelError)
+	time.Sleep(30 * time.Millisecond)
+	s.Equal(slog.LevelError, s.log.Level())
+}
+
+func (s *LevelSuite) TestLevelHandler() {
+	w, state := s.serve(http.MethodGet, "/", "")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(map[string]any{"level": "INFO"}, state)
+
+	w, state = s.serve(http.MethodPut, "/", `{"level": "debug"}`)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(map[string]any{"level": "DEBUG"}, state)
+	s.Equal(slog.LevelDebug, s.log.Level())
+
+	w, state = s.serve(http.MethodPost, "/?level=warn&revert_after=1h", "")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("WARN", state["level"])
+	revertAt, err := time.Parse(time.RFC3339, state["revert_at"].(string))
+	s.Nil(err)
+	s.WithinDuration(time.Now().Add(time.Hour), revertAt, time.Minute)
+
+	_, state = s.serve(http.MethodGet, "/", "")
+	s.Contains(state, "revert_at")
+
+	s.log.SetLevel(slog.LevelInfo)
+	_, state = s.serve(http.MethodGet, "/", "")
+	s.Equal(map[string]any{"level": "INFO"}, state)
+}
+
+func (s *LevelSuite) TestLevelHandler_Invalid() {
+	cases := []struct {
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{http.MethodPut, "/", `{"level": "verbose"}`, http.StatusBadRequest},
+		{http.MethodPut, "/", ``, http.StatusBadRequest},
+		{http.MethodPut, "/", `{}`, http.StatusBadRequest},
+		{http.MethodPut, "/", `{"levl": "debug"}`, http.StatusBadRequest},
+		{http.MethodPut, "/", `{"revert_after": "1m"}`, http.StatusBadRequest},
+		{http.MethodPut, "/", `{"level": "debug", "extra": 1}`, http.StatusBadRequest},
+		{http.MethodPut, "/", `{"level": "debug", "revert_after": "` + strings.Repeat("1", 2<<10) + `s"}`, http.StatusBadRequest},
+		{http.MethodPut, "/?level=debug&revert_after=soon", "", http.StatusBadRequest},
+		{http.MethodPut, "/", `{"level": "debug", "revert_after": "-1m"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, c := range cases {
+		w, _ := s.serve(c.method, c.target, c.body)
+
+		s.Equal(c.status, w.Code, c.target+c.body)
+		s.Equal(slog.LevelInfo, s.log.Level())
+	}
+}
+
+func TestLevelSuite(t *testing.T) {
+	suite.Run(t, new(LevelSuite))
+}
@@ -13,13 +13,25 @@ type LoggerOps struct {
 	Level           slog.Level
 	AddSource       bool
 	SourceFieldName string
-	CallerSkip      int // Adjust this based on your debugCaller() output
+	ShortSource     bool           // file name only instead of the full path
+	ModuleSource    bool           // path relative to the main module root
+	SourceFunction  bool           // append the function name to the source
+	TraceExtractor  TraceExtractor // adds trace_id and span_id to records of traced contexts
+	Redact          *RedactOps     // redacts sensitive attributes, nil to log everything as is
+	// ComponentLevels sets the levels of named loggers, see ParseComponentLevels.
+	ComponentLevels map[string]slog.Level
+	Sampling        *SamplingOps // drops and collapses records of hot paths, nil to log every record
+	Async           *AsyncOps    // writes records from a background goroutine, nil to write them synchronously
+	// Deprecated: the source is resolved from the record PC, mark wrapper functions with Helper instead.
+	CallerSkip int
 }
 
 type Logger struct {
 	*slog.Logger
 	handler *ctxLoggerHandler
 	opts    *LoggerOps
+	level   *levelControl
+	async   *asyncQueue
 }
 
 func NewLoggerOps(JSON bool, Writer io.Writer, level slog.Level, addSource bool, sourceFieldName string, callerSkip int) *LoggerOps {
@@ -39,22 +51,60 @@ func NewLoggerOps(JSON bool, Writer io.Writer, level slog.Level, addSource bool,
 func New(opts *LoggerOps, wrapHandler func(handler slog.Handler) slog.Handler) *Logger {
 	var handler slog.Handler
 
+	// The level is held by a LevelVar so it can be changed at runtime,
+	// records are filtered by ctxLoggerHandler with the level of their component
+	level := newLevelControl(opts)
+	handlerOpts := slog.HandlerOptions{}
+	if opts.HandlerOptions != nil {
+		handlerOpts = *opts.HandlerOptions
+	}
+	handlerOpts.Level = level
+
+	writer := opts.Writer
+	var batch *batchWriter
+	if opts.Async != nil {
+		batch = &batchWriter{w: opts.Writer}
+		writer = batch
+	}
+
 	if opts.JSON {
-		handler = slog.NewJSONHandler(opts.Writer, opts.HandlerOptions)
+		handler = slog.NewJSONHandler(writer, &handlerOpts)
 	} else {
-		handler = slog.NewTextHandler(opts.Writer, opts.HandlerOptions)
+		handler = slog.NewTextHandler(writer, &handlerOpts)
 	}
 
 	if wrapHandler != nil {
 		handler = wrapHandler(handler)
 	}
 
-	ctxHandler := &ctxLoggerHandler{Handler: handler, AddSource: opts.AddSource, SourceFieldName: opts.SourceFieldName, CallerSkip: opts.CallerSkip}
+	var async *asyncQueue
+	if opts.Async != nil {
+		async = newAsyncQueue(opts.Async, batch, handler)
+		handler = &asyncHandler{handler: handler, queue: async}
+	}
+
+	ctxHandler := &ctxLoggerHandler{
+		Handler:         handler,
+		root:            handler,
+		base:            handler,
+		AddSource:       opts.AddSource,
+		SourceFieldName: opts.SourceFieldName,
+		ShortSource:     opts.ShortSource,
+		ModuleSource:    opts.ModuleSource,
+		SourceFunction:  opts.SourceFunction,
+		TraceExtractor:  opts.TraceExtractor,
+		redactor:        newRedactor(opts.Redact),
+		levels:          level,
+		sampler:         newSampler(opts.Sampling),
+		deduper:         newDeduper(opts.Sampling),
+	}
 
 	return &Logger{
 		Logger:  slog.New(ctxHandler),
 		handler: ctxHandler,
 		opts:    opts,
+		level:   level,
+		async:   async,
 	}
 }
 
@@ -2,46 +2,151 @@ package logger
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
-	"runtime"
+	"slices"
 )
 
 type ctxKey struct{}
 
 type ctxLoggerHandler struct {
 	slog.Handler
+	// root is the wrapped handler before the first group, base adds the component to it
+	// and ops replay the attrs and groups since. Handler is built once from base and ops,
+	// they are only replayed for grouped records with context attrs or a source,
+	// which must be added to base to stay at the top level.
+	root slog.Handler
+	base slog.Handler
+	ops  []handlerOp
+
 	AddSource       bool
 	SourceFieldName string
-	CallerSkip      int // Adjust this based on your debugCaller() output
+	ShortSource     bool
+	ModuleSource    bool
+	SourceFunction  bool
+	TraceExtractor  TraceExtractor
+	redactor        *redactor
+	levels          *levelControl
+	component       string
+	sampler         *sampler
+	deduper         *deduper
+}
+
+// handlerOp is either a group or a list of attrs applied to the handler.
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
 }
 
 var _ slog.Handler = (*ctxLoggerHandler)(nil)
 
+func (h *ctxLoggerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.levels != nil && level < h.levels.componentLevel(h.component) {
+		return false
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
 func (h *ctxLoggerHandler) Handle(ctx context.Context, r slog.Record) error {
-	if attrs, ok := ctx.Value(ctxKey{}).([]slog.Attr); ok {
-		for _, v := range attrs {
-			r.AddAttrs(v)
-		}
+	if h.sampler != nil && !h.sampler.sample(h.component, r) {
+		return nil
+	}
+
+	if h.redactor != nil {
+		r = h.redactor.redactRecord(r)
+	}
+
+	attrs := h.contextAttrs(ctx)
+	if trace := h.traceAttrs(ctx); trace != nil {
+		attrs = slices.Concat(attrs, trace)
 	}
 
 	if h.AddSource {
-		_, file, line, ok := runtime.Caller(h.CallerSkip)
-		if ok {
-			if h.SourceFieldName == "" {
-				h.SourceFieldName = "source"
+		if frame, ok := callerFrame(r.PC); ok {
+			fieldName := h.SourceFieldName
+			if fieldName == "" {
+				fieldName = "source"
 			}
 
-			r.AddAttrs(slog.String(h.SourceFieldName, fmt.Sprintf("%s:%d", file, line)))
+			attrs = append(attrs[:len(attrs):len(attrs)], slog.String(fieldName, h.formatSource(frame)))
+		}
+	}
+
+	if h.deduper != nil && h.deduper.suppress(h, r, attrs) {
+		return nil
+	}
+	return h.handle(ctx, r, attrs)
+}
+
+// handle writes the record with attrs added at the top level, outside of the handler groups.
+func (h *ctxLoggerHandler) handle(ctx context.Context, r slog.Record, attrs []slog.Attr) error {
+	if len(attrs) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+	if len(h.ops) == 0 {
+		r.AddAttrs(attrs...)
+		return h.Handler.Handle(ctx, r)
+	}
+
+	return h.replay(h.base.WithAttrs(attrs)).Handle(ctx, r)
+}
+
+// replay applies the attrs and groups of ops to handler.
+func (h *ctxLoggerHandler) replay(handler slog.Handler) slog.Handler {
+	for _, op := range h.ops {
+		if op.group != "" {
+			handler = handler.WithGroup(op.group)
+		} else {
+			handler = handler.WithAttrs(op.attrs)
 		}
 	}
-	return h.Handler.Handle(ctx, r)
+	return handler
+}
+
+func (h *ctxLoggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	if h.redactor != nil {
+		attrs = h.redactor.redactAttrs(attrs)
+	}
+
+	h2 := *h
+	h2.Handler = h.Handler.WithAttrs(attrs)
+	switch {
+	case len(h.ops) == 0:
+		h2.base = h2.Handler
+		// root doesn't hold the component, so Named can replace it
+		h2.root = h2.Handler
+		if h.component != "" {
+			h2.root = h.root.WithAttrs(attrs)
+		}
+	case h.ops[len(h.ops)-1].group == "":
+		// Merge with the previous attrs so replays call WithAttrs once per group
+		h2.ops = slices.Clone(h.ops)
+		last := &h2.ops[len(h2.ops)-1]
+		last.attrs = slices.Concat(last.attrs, attrs)
+	default:
+		h2.ops = append(slices.Clip(h.ops), handlerOp{attrs: attrs})
+	}
+	return &h2
+}
+
+func (h *ctxLoggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.Handler = h.Handler.WithGroup(name)
+	h2.ops = append(slices.Clip(h.ops), handlerOp{group: name})
+	return &h2
 }
 
 func (h *ctxLoggerHandler) WithValue(parent context.Context, attrs ...slog.Attr) context.Context {
 	if v, ok := parent.Value(ctxKey{}).([]slog.Attr); ok {
-		v = append(v, attrs...)
-		return context.WithValue(parent, ctxKey{}, v)
+		// Copy so sibling contexts derived from the same parent don't share attrs
+		return context.WithValue(parent, ctxKey{}, slices.Concat(v, attrs))
 	}
 
 	return context.WithValue(parent, ctxKey{}, attrs)
@@ -0,0 +1,187 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+type LoggerHandlerSuite struct {
+	suite.Suite
+	buf *bytes.Buffer
+	log *logger.Logger
+}
+
+func (s *LoggerHandlerSuite) SetupTest() {
+	s.buf = &bytes.Buffer{}
+	s.log = logger.New(logger.NewLoggerOps(true, s.buf, slog.LevelDebug, false, "", 0), nil)
+}
+
+func (s *LoggerHandlerSuite) records() []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s.buf.String()), "\n") {
+		var record map[string]any
+		s.Require().Nil(json.Unmarshal([]byte(line), &record))
+		delete(record, "time")
+		records = append(records, record)
+	}
+	return records
+}
+
+func (s *LoggerHandlerSuite) TestWithValue() {
+	ctx := s.log.WithValue(context.Background(), slog.String("request_id", "r1"))
+
+	s.log.InfoContext(ctx, "hello", "user", "alice")
+
+	s.Equal([]map[string]any{
+		{"level": "INFO", "msg": "hello", "user": "alice", "request_id": "r1"},
+	}, s.records())
+}
+
+func (s *LoggerHandlerSuite) TestWithValue_SiblingContexts() {
+	parent := s.log.WithValue(context.Background(), slog.String("a", "1"))
+	parent = s.log.WithValue(parent, slog.String("b", "2"))
+	first := s.log.WithValue(parent, slog.String("c", "3"))
+	second := s.log.WithValue(parent, slog.String("d", "4"))
+
+	s.log.InfoContext(first, "first")
+	s.log.InfoContext(second, "second")
+
+	records := s.records()
+	s.Equal("3", records[0]["c"])
+	s.NotContains(records[0], "d")
+	s.Equal("4", records[1]["d"])
+	s.NotContains(records[1], "c")
+}
+
+func (s *LoggerHandlerSuite) TestWith_KeepsContextAttrs() {
+	ctx := s.log.WithValue(context.Background(), slog.String("request_id", "r1"))
+	derived := s.log.With("component", "db")
+
+	derived.InfoContext(ctx, "query")
+
+	s.Equal([]map[string]any{
+		{"level": "INFO", "msg": "query", "component": "db", "request_id": "r1"},
+	}, s.records())
+}
+
+func (s *LoggerHandlerSuite) TestWithGroup_ContextAttrsAtTopLevel() {
+	ctx := s.log.WithValue(context.Background(), slog.String("request_id", "r1"))
+	derived := s.log.With("service", "api").WithGroup("http").With("method", "GET").WithGroup("response")
+
+	derived.InfoContext(ctx, "done", "status", 200)
+	derived.Info("no context", "status", 500)
+
+	s.Equal([]map[string]any{
+		{
+			"level":      "INFO",
+			"msg":        "done",
+			"service":    "api",
+			"request_id": "r1",
+			"http": map[string]any{
+				"method":   "GET",
+				"response": map[string]any{"status": float64(200)},
+			},
+		},
+		{
+			"level":   "INFO",
+			"msg":     "no context",
+			"service": "api",
+			"http": map[string]any{
+				"method":   "GET",
+				"response": map[string]any{"status": float64(500)},
+			},
+		},
+	}, s.records())
+}
+
+func (s *LoggerHandlerSuite) TestWithGroup_KeepsSource() {
+	log := logger.New(logger.NewLoggerOps(true, s.buf, slog.LevelInfo, true, "src", 0), nil)
+
+	log.WithGroup("g").Info("grouped", "k", "v")
+
+	records := s.records()
+	s.Equal(map[string]any{"k": "v"}, records[0]["g"])
+	s.Contains(records[0]["src"], "logger_handler_test.go:")
+}
+
+// derivingHandler counts the handlers derived from it with WithAttrs and WithGroup.
+type derivingHandler struct {
+	slog.Handler
+	derived *atomic.Int64
+}
+
+func (h derivingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.derived.Add(1)
+	return derivingHandler{Handler: h.Handler.WithAttrs(attrs), derived: h.derived}
+}
+
+func (h derivingHandler) WithGroup(name string) slog.Handler {
+	h.derived.Add(1)
+	return derivingHandler{Handler: h.Handler.WithGroup(name), derived: h.derived}
+}
+
+func (s *LoggerHandlerSuite) TestWithGroup_ReplaysOnlyForContextAttrs() {
+	derived := &atomic.Int64{}
+	log := logger.New(logger.NewLoggerOps(true, s.buf, slog.LevelInfo, false, "", 0), func(h slog.Handler) slog.Handler {
+		return derivingHandler{Handler: h, derived: derived}
+	})
+	grouped := log.Named("db").With("a", 1).WithGroup("g").With("b", 2).With("c", 3)
+
+	before := derived.Load()
+	for i := 0; i < 10; i++ {
+		grouped.Info("no context")
+	}
+	s.Equal(before, derived.Load())
+
+	// Context attrs go to the top level: base with them, then the group and its merged attrs
+	ctx := log.WithValue(context.Background(), slog.String("request_id", "r1"))
+	grouped.InfoContext(ctx, "with context")
+	s.Equal(before+3, derived.Load())
+
+	records := s.records()
+	s.Equal(map[string]any{
+		"level": "INFO", "msg": "with context", "component": "db", "a": float64(1), "request_id": "r1",
+		"g": map[string]any{"b": float64(2), "c": float64(3)},
+	}, records[len(records)-1])
+	s.Equal("db", records[0]["component"])
+}
+
+func (s *LoggerHandlerSuite) TestSlogTest() {
+	slogtest.Run(s.T(), func(t *testing.T) slog.Handler {
+		s.buf.Reset()
+		return s.log.Handler()
+	}, func(t *testing.T) map[string]any {
+		var record map[string]any
+		s.Require().Nil(json.Unmarshal(s.buf.Bytes(), &record))
+		return record
+	})
+}
+
+func (s *LoggerHandlerSuite) TestSlogTest_TestHandler() {
+	s.buf.Reset()
+
+	err := slogtest.TestHandler(s.log.Handler(), func() []map[string]any {
+		var records []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(s.buf.Bytes()), []byte("\n")) {
+			var record map[string]any
+			s.Require().Nil(json.Unmarshal(line, &record))
+			records = append(records, record)
+		}
+		return records
+	})
+
+	s.Nil(err)
+}
+
+func TestLoggerHandlerSuite(t *testing.T) {
+	suite.Run(t, new(LoggerHandlerSuite))
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RequestIDHeader is the header propagating request IDs between services.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// HTTPLogOps configures the HTTP logging middleware.
+type HTTPLogOps struct {
+	Message      string             // message of the access log line
+	StatusLevels map[int]slog.Level // access log level by status class, 2 for 2xx and so on, Info when missing
+	SkipPaths    []string           // paths without access log, such as health checks
+	// GenerateRequestID creates the ID of requests without a valid X-Request-ID header.
+	GenerateRequestID func() string
+}
+
+// NewHTTPLogOps creates middleware options logging 4xx responses as warnings and 5xx responses as errors.
+func NewHTTPLogOps(skipPaths ...string) *HTTPLogOps {
+	return &HTTPLogOps{
+		Message: "http request",
+		StatusLevels: map[int]slog.Level{
+			4: slog.LevelWarn,
+			5: slog.LevelError,
+		},
+		SkipPaths:         skipPaths,
+		GenerateRequestID: newRequestID,
+	}
+}
+
+// RequestIDFromContext returns the request ID set by the HTTP logging middleware.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// HTTPMiddleware returns a middleware that propagates the X-Request-ID header, or generates one,
+// and adds the request ID, method, path, remote address and user agent to the request context with WithValue.
+// Once the handler returns it logs an access line with the status code, bytes written and latency.
+func (l *Logger) HTTPMiddleware(opts *HTTPLogOps) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = NewHTTPLogOps()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = opts.requestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+			ctx = l.WithValue(ctx,
+				slog.String("request_id", requestID),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+			)
+			r = r.WithContext(ctx)
+
+			if slices.Contains(opts.SkipPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				// A panicking handler is logged as a server error, then the panic goes on to the server
+				v := recover()
+				if v != nil && sw.status == 0 {
+					sw.status = http.StatusInternalServerError
+				}
+				l.LogAttrs(ctx, opts.level(sw.statusCode()), opts.message(),
+					slog.Int("status", sw.statusCode()),
+					slog.Int64("bytes", sw.bytes),
+					slog.Duration("latency", time.Since(start)),
+				)
+				if v != nil {
+					panic(v)
+				}
+			}()
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+func (o *HTTPLogOps) level(status int) slog.Level {
+	if level, ok := o.StatusLevels[status/100]; ok {
+		return level
+	}
+	return slog.LevelInfo
+}
+
+func (o *HTTPLogOps) message() string {
+	if o.Message == "" {
+		return "http request"
+	}
+	return o.Message
+}
+
+func (o *HTTPLogOps) requestID() string {
+	if o.GenerateRequestID == nil {
+		return newRequestID()
+	}
+	return o.GenerateRequestID()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID rejects IDs that could forge log lines or bloat every record.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// statusWriter records the status code and the number of bytes written to a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	// Informational responses are followed by the final one
+	if w.status == 0 && statusCode >= 200 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,151 @@
+package logger_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+type MiddlewareSuite struct {
+	suite.Suite
+	buf *syncBuffer
+	log *logger.Logger
+}
+
+func (s *MiddlewareSuite) SetupTest() {
+	s.buf = &syncBuffer{}
+	s.log = logger.New(logger.NewLoggerOps(true, s.buf, slog.LevelDebug, false, "", 0), nil)
+}
+
+func (s *MiddlewareSuite) serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.log.HTTPMiddleware(nil)(h).ServeHTTP(w, req)
+	return w
+}
+
+func (s *MiddlewareSuite) TestAccessLog() {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.log.InfoContext(r.Context(), "handling")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	req := httptest.NewRequest(http.MethodPost, "/users?x=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set(logger.RequestIDHeader, "abc-123")
+
+	w := s.serve(h, req)
+
+	s.Equal("abc-123", w.Header().Get(logger.RequestIDHeader))
+	records := s.buf.records()
+	s.Len(records, 2)
+	for _, record := range records {
+		s.Equal("abc-123", record["request_id"])
+		s.Equal("POST", record["method"])
+		s.Equal("/users", record["path"])
+		s.Equal("192.0.2.1:1234", record["remote_addr"])
+		s.Equal("test-agent", record["user_agent"])
+	}
+	s.Equal("handling", records[0]["msg"])
+
+	access := records[1]
+	s.Equal("http request", access["msg"])
+	s.Equal("INFO", access["level"])
+	s.Equal(float64(http.StatusCreated), access["status"])
+	s.Equal(float64(5), access["bytes"])
+	s.Contains(access, "latency")
+}
+
+func (s *MiddlewareSuite) TestRequestID_Generated() {
+	var fromContext string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = logger.RequestIDFromContext(r.Context())
+	})
+
+	cases := []string{"", "bad id\nlevel=ERROR", strings.Repeat("a", 200)}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(logger.RequestIDHeader, c)
+
+		w := s.serve(h, req)
+
+		id := w.Header().Get(logger.RequestIDHeader)
+		s.Len(id, 32, c)
+		s.Equal(id, fromContext, c)
+	}
+}
+
+func (s *MiddlewareSuite) TestStatusLevels() {
+	cases := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "INFO"},
+		{http.StatusFound, "INFO"},
+		{http.StatusNotFound, "WARN"},
+		{http.StatusServiceUnavailable, "ERROR"},
+	}
+
+	for i, c := range cases {
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+		})
+
+		s.serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		s.Equal(c.level, s.buf.records()[i]["level"], c.status)
+	}
+}
+
+func (s *MiddlewareSuite) TestCustomOps() {
+	opts := logger.NewHTTPLogOps("/healthz")
+	opts.Message = "access"
+	opts.StatusLevels[2] = slog.LevelDebug
+	opts.GenerateRequestID = func() string { return "fixed" }
+	h := s.log.HTTPMiddleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	s.Empty(s.buf.records())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	records := s.buf.records()
+	s.Len(records, 1)
+	s.Equal("access", records[0]["msg"])
+	s.Equal("DEBUG", records[0]["level"])
+	s.Equal("fixed", w.Header().Get(logger.RequestIDHeader))
+}
+
+func (s *MiddlewareSuite) TestPanic() {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	s.PanicsWithValue("boom", func() {
+		s.serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	records := s.buf.records()
+	s.Len(records, 1)
+	s.Equal("ERROR", records[0]["level"])
+	s.Equal(float64(http.StatusInternalServerError), records[0]["status"])
+}
+
+func (s *MiddlewareSuite) TestFlush() {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Nil(http.NewResponseController(w).Flush())
+	})
+
+	w := s.serve(h, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.True(w.Flushed)
+	s.Equal(float64(http.StatusOK), s.buf.records()[0]["status"])
+}
+
+func TestMiddlewareSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareSuite))
+}
@@ -0,0 +1,335 @@
+package logger
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RedactTag is the struct tag marking fields whose value must never be logged:
+//
+//	type User struct {
+//		Name     string `json:"name"`
+//		Password string `json:"password" log:"redact"`
+//	}
+const RedactTag = "log"
+
+const DefaultRedactReplacement = "[REDACTED]"
+
+// Common patterns of sensitive string values. Matches of RedactCreditCards are only redacted
+// when their digits pass the Luhn checksum.
+var (
+	RedactCreditCards = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	RedactJWTs        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	RedactEmails      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// RedactOps configures the redaction of sensitive data, applied to record attributes,
+// attributes of derived loggers and attributes added with WithValue, recursively through groups,
+// struct fields, slices, arrays, maps and pointers. Keys also match struct field names and map keys.
+type RedactOps struct {
+	Keys        []string         // case-insensitive glob patterns of keys whose whole value is redacted, such as "*password*"
+	Patterns    []*regexp.Regexp // parts of string values matching any pattern are redacted
+	Replacement string           // DefaultRedactReplacement when empty
+}
+
+// NewRedactOps creates redaction options for the given key patterns,
+// along with RedactCreditCards and RedactJWTs for values.
+func NewRedactOps(keys ...string) *RedactOps {
+	return &RedactOps{
+		Keys:        keys,
+		Patterns:    []*regexp.Regexp{RedactCreditCards, RedactJWTs},
+		Replacement: DefaultRedactReplacement,
+	}
+}
+
+type redactor struct {
+	keys        []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+func newRedactor(opts *RedactOps) *redactor {
+	if opts == nil {
+		return nil
+	}
+
+	r := &redactor{patterns: opts.Patterns, replacement: opts.Replacement}
+	if r.replacement == "" {
+		r.replacement = DefaultRedactReplacement
+	}
+	for _, key := range opts.Keys {
+		r.keys = append(r.keys, strings.ToLower(key))
+	}
+	return r
+}
+
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, pattern := range r.patterns {
+		if pattern == RedactCreditCards {
+			// Digit runs such as order numbers and timestamps are only card numbers if they pass the Luhn check
+			s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+				if luhnValid(match) {
+					return r.replacement
+				}
+				return match
+			})
+			continue
+		}
+		s = pattern.ReplaceAllLiteralString(s, r.replacement)
+	}
+	return s
+}
+
+// luhnValid reports whether the digits of s, ignoring separators, pass the Luhn checksum of card numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		digit := int(s[i] - '0')
+		if double {
+			if digit *= 2; digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func (r *redactor) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = r.redactAttr(a)
+	}
+	return redacted
+}
+
+func (r *redactor) redactAttr(a slog.Attr) slog.Attr {
+	if r.matchKey(a.Key) {
+		return slog.String(a.Key, r.replacement)
+	}
+
+	// LogValuer results are redacted like any other value
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.redactString(a.Value.String()))
+	case slog.KindGroup:
+		a.Value = slog.GroupValue(r.redactAttrs(a.Value.Group())...)
+	case slog.KindAny:
+		if v := reflect.ValueOf(a.Value.Any()); v.IsValid() && mayRedact(v.Type()) {
+			a.Value = r.redactReflect(v, 0)
+		}
+	}
+	return a
+}
+
+// maxRedactDepth bounds the walk of nested values, cyclic pointers being redacted past it.
+const maxRedactDepth = 32
+
+// redactReflect walks values that may hold sensitive data. Structs and maps become groups,
+// keyed like encoding/json for structs, slices and arrays become []any of their redacted elements.
+// Tagged struct fields and values of map keys matching the key patterns are redacted.
+func (r *redactor) redactReflect(v reflect.Value, depth int) slog.Value {
+	if depth > maxRedactDepth {
+		return slog.StringValue(r.replacement)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return slog.AnyValue(v.Interface())
+		}
+		return r.redactEntry("", v.Elem(), depth+1).Value
+	case reflect.Struct:
+		fields := redactFields(v.Type())
+		attrs := make([]slog.Attr, 0, len(fields))
+		for _, f := range fields {
+			if f.redact {
+				attrs = append(attrs, slog.String(f.name, r.replacement))
+				continue
+			}
+			attrs = append(attrs, r.redactEntry(f.name, v.Field(f.index), depth+1))
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Map:
+		if v.IsNil() {
+			return slog.AnyValue(v.Interface())
+		}
+		attrs := make([]slog.Attr, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key := fmt.Sprint(iter.Key().Interface())
+			attrs = append(attrs, r.redactEntry(key, iter.Value(), depth+1))
+		}
+		// Map iteration order is random, keep records stable
+		slices.SortFunc(attrs, func(a, b slog.Attr) int { return strings.Compare(a.Key, b.Key) })
+		return slog.GroupValue(attrs...)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return slog.AnyValue(v.Interface())
+		}
+		elems := make([]any, v.Len())
+		for i := range elems {
+			elems[i] = plainValue(r.redactEntry("", v.Index(i), depth+1).Value)
+		}
+		return slog.AnyValue(elems)
+	default:
+		return slog.AnyValue(v.Interface())
+	}
+}
+
+// redactEntry redacts a struct field, map entry or element, the key being empty for elements.
+func (r *redactor) redactEntry(key string, v reflect.Value, depth int) slog.Attr {
+	if key != "" && r.matchKey(key) {
+		return slog.String(key, r.replacement)
+	}
+	if mayRedact(v.Type()) {
+		return slog.Attr{Key: key, Value: r.redactReflect(v, depth)}
+	}
+	// Strings and LogValuers are redacted, other values are left to the handler
+	value := slog.AnyValue(v.Interface()).Resolve()
+	if value.Kind() == slog.KindString {
+		value = slog.StringValue(r.redactString(value.String()))
+	} else if value.Kind() == slog.KindGroup {
+		value = slog.GroupValue(r.redactAttrs(value.Group())...)
+	}
+	return slog.Attr{Key: key, Value: value}
+}
+
+// plainValue converts a redacted value back to a Go value, groups becoming maps,
+// for the elements of slices which handlers don't expect to hold slog values.
+func plainValue(v slog.Value) any {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+	m := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		m[a.Key] = plainValue(a.Value)
+	}
+	return m
+}
+
+var (
+	mayRedactCache sync.Map // reflect.Type -> bool
+
+	errorType         = reflect.TypeFor[error]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	logValuerType     = reflect.TypeFor[slog.LogValuer]()
+)
+
+// mayRedact reports whether values of t can hold tagged struct fields or maps keyed by strings,
+// directly or through pointers, slices, arrays, maps, struct fields and interfaces.
+// Types encoding themselves, such as errors and marshalers, are left to the handler.
+func mayRedact(t reflect.Type) bool {
+	if cached, ok := mayRedactCache.Load(t); ok {
+		return cached.(bool)
+	}
+	// Only the result of t is cached, types on a cycle through t are resolved from their own walk
+	result := reachesRedactable(t, map[reflect.Type]bool{})
+	mayRedactCache.Store(t, result)
+	return result
+}
+
+func reachesRedactable(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	if t.Implements(errorType) || t.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || t.Implements(logValuerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String || reachesRedactable(t.Elem(), visited)
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return reachesRedactable(t.Elem(), visited)
+	case reflect.Struct:
+		for _, f := range redactFields(t) {
+			if f.redact || reachesRedactable(t.Field(f.index).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type redactField struct {
+	name   string
+	index  int
+	redact bool
+}
+
+var redactFieldsCache sync.Map // reflect.Type -> []redactField
+
+// redactFields lists the exported fields of a struct, named like encoding/json.
+func redactFields(t reflect.Type) []redactField {
+	if cached, ok := redactFieldsCache.Load(t); ok {
+		return cached.([]redactField)
+	}
+
+	var fields []redactField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, redactField{name: name, index: i, redact: sf.Tag.Get(RedactTag) == "redact"})
+	}
+
+	redactFieldsCache.Store(t, fields)
+	return fields
+}
+
+// redactRecord returns a copy of the record with its attributes redacted.
+func (r *redactor) redactRecord(record slog.Record) slog.Record {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(r.redactAttr(a))
+		return true
+	})
+	return redacted
+}
+
+// contextAttrs returns the attributes added to ctx with WithValue, redacted.
+func (h *ctxLoggerHandler) contextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	if h.redactor == nil || len(attrs) == 0 {
+		return attrs
+	}
+	return h.redactor.redactAttrs(attrs)
+}
@@ -0,0 +1,397 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotateRetryDelay is how long Write waits before trying again a rotation that failed.
+const rotateRetryDelay = time.Minute
+
+var (
+	// rename moves the current file to its backup name, replaced in tests to simulate failures.
+	rename = os.Rename
+	// now is the clock of rotations, replaced in tests to cross intervals without waiting.
+	now = time.Now
+)
+
+// RotateOps configures a RotatingFile.
+type RotateOps struct {
+	Filename   string        // path of the current log file, its directory is created when missing
+	MaxSize    int64         // rotates before the file grows over MaxSize bytes, 0 to disable
+	Interval   time.Duration // rotates at every multiple of Interval since the Unix epoch, such as 24h for midnight UTC, 0 to disable
+	MaxBackups int           // rotated files kept, 0 to keep them all
+	MaxAge     time.Duration // rotated files older than MaxAge are removed, 0 to keep them all
+	Compress   bool          // gzip rotated files
+	// ErrorHandler receives the errors of the background work: compressing and removing backups
+	// and reopening the file on SIGHUP. They are discarded when it is nil.
+	ErrorHandler func(error)
+}
+
+// RotatingFile is an io.Writer appending to a file that it rotates by size and time.
+// Rotated files are renamed with their rotation time, "app.log" becoming "app-2024-01-15T10-30-00.000.log",
+// then compressed and removed according to the retention options in the background.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	opts RotateOps
+
+	mu            sync.Mutex
+	file          *os.File
+	size          int64
+	nextRotation  time.Time
+	retryRotation time.Time // no rotation is attempted by Write before, after a failed one
+
+	mill     chan struct{}
+	millDone chan struct{}
+	signals  chan os.Signal
+	closed   bool
+}
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// NewRotatingFile opens opts.Filename for appending, creating it when missing.
+func NewRotatingFile(opts RotateOps) (*RotatingFile, error) {
+	if opts.Filename == "" {
+		return nil, errors.New("logger: missing rotating file name")
+	}
+
+	f := &RotatingFile{
+		opts:     opts,
+		mill:     make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	go f.runMill()
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.opts.Filename), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.opts.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file, f.size = file, info.Size()
+	// An existing file rotates on its first write when it was last written during a previous interval
+	f.nextRotation = time.Time{}
+	if f.opts.Interval > 0 {
+		f.nextRotation = nextBoundary(info.ModTime(), f.opts.Interval)
+	}
+	return nil
+}
+
+// nextBoundary returns the first multiple of interval since the Unix epoch after t.
+// time.Time.Truncate counts from the zero time instead, which is not aligned on weekly intervals.
+func nextBoundary(t time.Time, interval time.Duration) time.Time {
+	ns := t.UnixNano()
+	return time.Unix(0, ns-ns%int64(interval)+int64(interval))
+}
+
+// Write appends p to the file, rotating it first when p would grow it over MaxSize or the interval ended.
+// When the rotation fails p is still appended to the current file and the rotation error is returned,
+// then Write keeps appending without rotating for a minute before trying again.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		// The file couldn't be opened by the last rotation or reopen, try again
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	t := now()
+	sizeExceeded := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	intervalEnded := !f.nextRotation.IsZero() && !t.Before(f.nextRotation)
+	if intervalEnded && f.size == 0 {
+		// Nothing was written during the interval, an empty backup is not worth keeping
+		f.nextRotation = nextBoundary(t, f.opts.Interval)
+		intervalEnded = false
+	}
+
+	var rotateErr error
+	if (sizeExceeded || intervalEnded) && !t.Before(f.retryRotation) {
+		if rotateErr = f.rotate(); rotateErr != nil {
+			f.retryRotation = t.Add(rotateRetryDelay)
+			if f.file == nil {
+				return 0, rotateErr
+			}
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+// Rotate rotates the file immediately.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// rotate moves the current file to a backup and opens a new one. When the file can't be moved,
+// the original is reopened so later writes still go through, and when it can't be opened
+// the file is left nil for Write to open it again.
+func (f *RotatingFile) rotate() error {
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	if err == nil {
+		if err = rename(f.opts.Filename, f.backupName(now())); errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+	}
+	if openErr := f.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	if err != nil {
+		return err
+	}
+	f.retryRotation = time.Time{}
+
+	select {
+	case f.mill <- struct{}{}:
+	default: // a pass is already pending, it will see this backup too
+	}
+	return nil
+}
+
+// Reopen closes and reopens the file by name, for external tools such as logrotate that move it away.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file != nil {
+		err := f.file.Close()
+		f.file = nil
+		if err != nil {
+			return err
+		}
+	}
+	return f.open()
+}
+
+// ReopenOnSIGHUP reopens the file whenever the process receives SIGHUP, as logrotate expects, until Close.
+func (f *RotatingFile) ReopenOnSIGHUP() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.signals != nil || f.closed {
+		return
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	f.signals = signals
+
+	go func() {
+		for range signals {
+			if err := f.Reopen(); err != nil {
+				f.handleError(fmt.Errorf("logger: reopening %s: %w", f.opts.Filename, err))
+			}
+		}
+	}()
+}
+
+// Close closes the file and waits for pending compression and cleanup.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+	f.closed = true
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.signals)
+	}
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+	}
+	close(f.mill)
+	f.mu.Unlock()
+
+	<-f.millDone
+	return err
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, base := filepath.Split(f.opts.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	name := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+	// Rotations within the same millisecond get a numbered name
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.UTC().Format(backupTimeFormat), i, ext))
+	}
+	return name
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// runMill compresses and removes backups after rotations, off the write path.
+func (f *RotatingFile) runMill() {
+	defer close(f.millDone)
+	for range f.mill {
+		if err := f.millBackups(); err != nil {
+			f.handleError(fmt.Errorf("logger: cleaning up backups of %s: %w", f.opts.Filename, err))
+		}
+	}
+}
+
+func (f *RotatingFile) handleError(err error) {
+	if f.opts.ErrorHandler != nil {
+		f.opts.ErrorHandler(err)
+	}
+}
+
+type backup struct {
+	name string
+	time time.Time
+	seq  int // number of rotations within the same millisecond
+}
+
+// backups lists the rotated files, newest first.
+func (f *RotatingFile) backups() ([]backup, error) {
+	dir, base := filepath.Split(f.opts.Filename)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+		seq := 0
+		if suffix := stamp[len(backupTimeFormat):]; suffix != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(suffix, ".")); err != nil || suffix[0] != '.' {
+				continue
+			}
+		}
+		backups = append(backups, backup{name: filepath.Join(dir, name), time: t, seq: seq})
+	}
+
+	slices.SortFunc(backups, func(a, b backup) int {
+		if c := b.time.Compare(a.time); c != 0 {
+			return c
+		}
+		return b.seq - a.seq
+	})
+	return backups, nil
+}
+
+func (f *RotatingFile) millBackups() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, b := range backups {
+		expired := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups ||
+			f.opts.MaxAge > 0 && now().Sub(b.time) > f.opts.MaxAge
+		switch {
+		case expired:
+			errs = append(errs, os.Remove(b.name))
+		case f.opts.Compress && !strings.HasSuffix(b.name, ".gz"):
+			errs = append(errs, compressFile(b.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compressFile replaces name with name.gz.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, name+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
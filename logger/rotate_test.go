@@ -0,0 +1,321 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+// clock is a settable time source for rotations.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+type RotateSuite struct {
+	suite.Suite
+	dir      string
+	filename string
+}
+
+func (s *RotateSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.filename = filepath.Join(s.dir, "logs", "app.log")
+}
+
+func (s *RotateSuite) files() []string {
+	entries, err := os.ReadDir(filepath.Dir(s.filename))
+	s.Require().Nil(err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func (s *RotateSuite) backups() []string {
+	return slices.DeleteFunc(s.files(), func(name string) bool { return name == "app.log" })
+}
+
+func (s *RotateSuite) read(name string) string {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(s.filename), name))
+	s.Require().Nil(err)
+	return string(data)
+}
+
+func (s *RotateSuite) write(f *logger.RotatingFile, text string) {
+	n, err := f.Write([]byte(text))
+	s.Require().Nil(err)
+	s.Require().Equal(len(text), n)
+}
+
+func (s *RotateSuite) TestMaxSize() {
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, MaxSize: 10})
+	s.Require().Nil(err)
+
+	s.write(f, "1234\n")
+	s.write(f, "5678\n")
+	s.write(f, "abcdef\n")
+	s.write(f, strings.Repeat("x", 20)+"\n") // larger than MaxSize, written to a file of its own
+	s.Nil(f.Close())
+
+	s.Equal(strings.Repeat("x", 20)+"\n", s.read("app.log"))
+	var contents []string
+	for _, name := range s.backups() {
+		s.Regexp(`^app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}(\.\d+)?\.log$`, name)
+		contents = append(contents, s.read(name))
+	}
+	s.ElementsMatch([]string{"1234\n5678\n", "abcdef\n"}, contents)
+}
+
+func (s *RotateSuite) TestAppendsToExistingFile() {
+	s.Require().Nil(os.MkdirAll(filepath.Dir(s.filename), 0o755))
+	s.Require().Nil(os.WriteFile(s.filename, []byte("12345678\n"), 0o644))
+
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, MaxSize: 10})
+	s.Require().Nil(err)
+	s.write(f, "abc\n")
+	s.Nil(f.Close())
+
+	s.Equal("abc\n", s.read("app.log"))
+	s.Len(s.backups(), 1)
+}
+
+func (s *RotateSuite) TestInterval() {
+	c := &clock{t: time.Now()}
+	defer logger.SetNow(c.now)()
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, Interval: time.Hour})
+	s.Require().Nil(err)
+
+	// The interval ending before anything is written leaves no empty backup
+	c.advance(time.Hour)
+	s.write(f, "first\n")
+	s.Empty(s.backups())
+
+	c.advance(time.Hour)
+	s.write(f, "second\n")
+	s.Nil(f.Close())
+
+	s.Equal("second\n", s.read("app.log"))
+	backups := s.backups()
+	s.Len(backups, 1)
+	s.Equal("first\n", s.read(backups[0]))
+}
+
+func (s *RotateSuite) TestRenameFails() {
+	c := &clock{t: time.Now()}
+	defer logger.SetNow(c.now)()
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, MaxSize: 10})
+	s.Require().Nil(err)
+	s.write(f, "12345678\n")
+
+	renames := 0
+	restore := logger.SetRename(func(string, string) error {
+		renames++
+		return syscall.EACCES
+	})
+	// The original file was reopened, the record is kept and the error reported once
+	n, err := f.Write([]byte("kept\n"))
+	s.ErrorIs(err, syscall.EACCES)
+	s.Equal(5, n)
+	s.write(f, "more\n")
+	restore()
+	s.Equal(1, renames)
+	s.Empty(s.backups())
+
+	// The rotation is tried again once the retry delay passed
+	c.advance(time.Minute)
+	s.write(f, "abc\n")
+	s.Nil(f.Close())
+
+	s.Equal("abc\n", s.read("app.log"))
+	backups := s.backups()
+	s.Len(backups, 1)
+	s.Equal("12345678\nkept\nmore\n", s.read(backups[0]))
+}
+
+func (s *RotateSuite) TestOpenFails() {
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename})
+	s.Require().Nil(err)
+	s.write(f, "first\n")
+
+	// A directory left in place of the file makes opening the new file fail
+	restore := logger.SetRename(func(oldpath, newpath string) error {
+		if err := os.Rename(oldpath, newpath); err != nil {
+			return err
+		}
+		return os.Mkdir(oldpath, 0o755)
+	})
+	s.NotNil(f.Rotate())
+	restore()
+
+	_, err = f.Write([]byte("lost\n"))
+	s.NotNil(err)
+
+	s.Require().Nil(os.Remove(s.filename))
+	s.write(f, "second\n")
+	s.Nil(f.Close())
+
+	s.Equal("second\n", s.read("app.log"))
+	backups := s.backups()
+	s.Len(backups, 1)
+	s.Equal("first\n", s.read(backups[0]))
+}
+
+func (s *RotateSuite) TestErrorHandler() {
+	errs := make(chan error, 1)
+	f, err := logger.NewRotatingFile(logger.RotateOps{
+		Filename:     s.filename,
+		ErrorHandler: func(err error) { errs <- err },
+	})
+	s.Require().Nil(err)
+	f.ReopenOnSIGHUP()
+
+	// A directory left in place of the file makes reopening it fail
+	s.Require().Nil(os.Remove(s.filename))
+	s.Require().Nil(os.Mkdir(s.filename, 0o755))
+	s.Require().Nil(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	select {
+	case err := <-errs:
+		s.ErrorContains(err, "reopening")
+	case <-time.After(time.Second):
+		s.Fail("no error reported")
+	}
+	s.Nil(f.Close())
+}
+
+func (s *RotateSuite) TestRetention() {
+	s.Require().Nil(os.MkdirAll(filepath.Dir(s.filename), 0o755))
+	old := filepath.Join(filepath.Dir(s.filename), "app-2001-01-01T00-00-00.000.log")
+	s.Require().Nil(os.WriteFile(old, []byte("old\n"), 0o644))
+	other := filepath.Join(filepath.Dir(s.filename), "other-2001-01-01T00-00-00.000.log")
+	s.Require().Nil(os.WriteFile(other, []byte("other\n"), 0o644))
+
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, MaxBackups: 2, MaxAge: 24 * time.Hour})
+	s.Require().Nil(err)
+	for i := 0; i < 4; i++ {
+		s.write(f, fmt.Sprintf("%d\n", i))
+		s.Nil(f.Rotate())
+	}
+	s.Nil(f.Close())
+
+	backups := s.backups()
+	s.Len(backups, 3)
+	s.Contains(backups, "other-2001-01-01T00-00-00.000.log")
+	var contents []string
+	for _, name := range backups {
+		if strings.HasPrefix(name, "app-") {
+			contents = append(contents, s.read(name))
+		}
+	}
+	s.ElementsMatch([]string{"2\n", "3\n"}, contents)
+}
+
+func (s *RotateSuite) TestCompress() {
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, Compress: true})
+	s.Require().Nil(err)
+
+	s.write(f, "compressed\n")
+	s.Nil(f.Rotate())
+	s.Nil(f.Close())
+
+	backups := s.backups()
+	s.Len(backups, 1)
+	s.True(strings.HasSuffix(backups[0], ".log.gz"), backups[0])
+
+	file, err := os.Open(filepath.Join(filepath.Dir(s.filename), backups[0]))
+	s.Require().Nil(err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	s.Require().Nil(err)
+	data, err := io.ReadAll(gz)
+	s.Nil(err)
+	s.Equal("compressed\n", string(data))
+}
+
+func (s *RotateSuite) TestReopen() {
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename})
+	s.Require().Nil(err)
+	f.ReopenOnSIGHUP()
+	moved := filepath.Join(s.dir, "moved.log")
+
+	s.write(f, "before\n")
+	s.Require().Nil(os.Rename(s.filename, moved))
+	s.write(f, "still moved\n")
+	s.Nil(f.Reopen())
+	s.write(f, "reopened\n")
+
+	s.Require().Nil(os.Rename(s.filename, moved+".2"))
+	s.Require().Nil(syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	s.Eventually(func() bool {
+		_, err := os.Stat(s.filename)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+	s.write(f, "after signal\n")
+	s.Nil(f.Close())
+
+	data, err := os.ReadFile(moved)
+	s.Nil(err)
+	s.Equal("before\nstill moved\n", string(data))
+	s.Equal("after signal\n", s.read("app.log"))
+	s.ErrorIs(f.Close(), os.ErrClosed)
+	_, err = f.Write([]byte("closed"))
+	s.ErrorIs(err, os.ErrClosed)
+}
+
+func (s *RotateSuite) TestConcurrentLogging() {
+	f, err := logger.NewRotatingFile(logger.RotateOps{Filename: s.filename, MaxSize: 1024})
+	s.Require().Nil(err)
+	log := logger.New(logger.NewLoggerOps(false, f, slog.LevelInfo, false, "", 0), nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				log.Info("concurrent", "worker", i, "n", j)
+			}
+		}()
+	}
+	wg.Wait()
+	s.Nil(f.Close())
+
+	lines := 0
+	for _, name := range s.files() {
+		content := s.read(name)
+		s.LessOrEqual(len(content), 1024, name)
+		lines += strings.Count(content, "msg=concurrent")
+	}
+	s.Equal(200, lines)
+}
+
+func TestRotateSuite(t *testing.T) {
+	suite.Run(t, new(RotateSuite))
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RepeatedKey is the attribute key of the number of duplicates collapsed by deduplication.
+const RepeatedKey = "repeated"
+
+// SamplingOps configures the sampling of records, keyed by component, level and message,
+// to keep hot paths from flooding the log pipeline.
+type SamplingOps struct {
+	Interval time.Duration // window of First, Thereafter and Dedupe, 1s when zero
+	// First records of a key are logged in each interval, then every Thereafter-th one,
+	// none when Thereafter is 0. Both 0 disables the counting.
+	First      int
+	Thereafter int
+	// LevelRatios keeps a random fraction of the records of a level, from 0 to 1, before counting.
+	// Levels without entry are all kept.
+	LevelRatios map[slog.Level]float64
+	// Dedupe logs the first record of a key in each interval and collapses the following ones
+	// into the last of them, logged at the end of the interval with the RepeatedKey count.
+	Dedupe bool
+}
+
+// NewSamplingOps creates sampling options logging first records of a key per second, then every thereafter-th one.
+func NewSamplingOps(first, thereafter int) *SamplingOps {
+	return &SamplingOps{
+		Interval:   time.Second,
+		First:      first,
+		Thereafter: thereafter,
+	}
+}
+
+type samplingKey struct {
+	component string
+	level     slog.Level
+	message   string
+}
+
+func newSamplingKey(component string, r slog.Record) samplingKey {
+	return samplingKey{component: component, level: r.Level, message: r.Message}
+}
+
+func samplingInterval(opts *SamplingOps) time.Duration {
+	if opts.Interval <= 0 {
+		return time.Second
+	}
+	return opts.Interval
+}
+
+// sampler counts the records of each key in the current interval.
+type sampler struct {
+	interval   time.Duration
+	first      int
+	thereafter int
+	ratios     map[slog.Level]float64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[samplingKey]int
+}
+
+func newSampler(opts *SamplingOps) *sampler {
+	if opts == nil || (opts.First <= 0 && opts.Thereafter <= 0 && len(opts.LevelRatios) == 0) {
+		return nil
+	}
+	return &sampler{
+		interval:   samplingInterval(opts),
+		first:      opts.First,
+		thereafter: opts.Thereafter,
+		ratios:     opts.LevelRatios,
+		counts:     map[samplingKey]int{},
+	}
+}
+
+// sample reports whether the record is logged.
+func (s *sampler) sample(component string, r slog.Record) bool {
+	if ratio, ok := s.ratios[r.Level]; ok && rand.Float64() >= ratio {
+		return false
+	}
+	if s.first <= 0 && s.thereafter <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// All counters start over together, which also bounds the memory to the keys of one interval
+	if now := time.Now(); now.Sub(s.windowStart) >= s.interval {
+		clear(s.counts)
+		s.windowStart = now
+	}
+	key := newSamplingKey(component, r)
+	n := s.counts[key] + 1
+	s.counts[key] = n
+
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// deduper collapses the records of a key logged during an interval.
+type deduper struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[samplingKey]*duplicates
+}
+
+// duplicates holds the last duplicate of a key, with what is needed to log it once the interval ends.
+type duplicates struct {
+	count   int
+	timer   *time.Timer
+	handler *ctxLoggerHandler
+	record  slog.Record
+	attrs   []slog.Attr
+}
+
+func newDeduper(opts *SamplingOps) *deduper {
+	if opts == nil || !opts.Dedupe {
+		return nil
+	}
+	return &deduper{interval: samplingInterval(opts), pending: map[samplingKey]*duplicates{}}
+}
+
+// suppress reports whether the record duplicates one logged in the current interval.
+func (d *deduper) suppress(h *ctxLoggerHandler, r slog.Record, attrs []slog.Attr) bool {
+	key := newSamplingKey(h.component, r)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if dup, ok := d.pending[key]; ok {
+		dup.count++
+		dup.handler, dup.record, dup.attrs = h, r.Clone(), attrs
+		return true
+	}
+
+	dup := &duplicates{}
+	dup.timer = time.AfterFunc(d.interval, func() { d.flush(key) })
+	d.pending[key] = dup
+	return false
+}
+
+// flush ends the interval of a key, logging its last duplicate with the repeat count.
+func (d *deduper) flush(key samplingKey) {
+	d.mu.Lock()
+	dup := d.pending[key]
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	if dup == nil || dup.count == 0 {
+		return
+	}
+	attrs := append(slices.Clip(dup.attrs), slog.Int(RepeatedKey, dup.count))
+	_ = dup.handler.handle(context.Background(), dup.record, attrs)
+}
+
+// flushAll ends every pending interval.
+func (d *deduper) flushAll() {
+	d.mu.Lock()
+	keys := make([]samplingKey, 0, len(d.pending))
+	for key, dup := range d.pending {
+		dup.timer.Stop()
+		keys = append(keys, key)
+	}
+	d.mu.Unlock()
+
+	for _, key := range keys {
+		d.flush(key)
+	}
+}
@@ -0,0 +1,183 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+// syncBuffer guards a buffer written by concurrent loggers and timers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) records() []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			panic(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+type SamplingSuite struct {
+	suite.Suite
+	buf *syncBuffer
+}
+
+func (s *SamplingSuite) SetupTest() {
+	s.buf = &syncBuffer{}
+}
+
+func (s *SamplingSuite) newLogger(sampling *logger.SamplingOps) *logger.Logger {
+	opts := logger.NewLoggerOps(true, s.buf, slog.LevelDebug, false, "", 0)
+	opts.Sampling = sampling
+	return logger.New(opts, nil)
+}
+
+func (s *SamplingSuite) count(msg string) int {
+	n := 0
+	for _, record := range s.buf.records() {
+		if record["msg"] == msg {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *SamplingSuite) TestFirstThereafter() {
+	log := s.newLogger(logger.NewSamplingOps(3, 5))
+
+	for i := 0; i < 20; i++ {
+		log.Error("hot")
+		log.Info("hot") // counted separately by level
+	}
+	log.Error("cold")
+	log.Named("db").Error("hot")
+
+	// 1, 2, 3, then 8, 13 and 18 of each level, plus the first of db
+	s.Equal(13, s.count("hot"))
+	s.Equal(1, s.count("cold"))
+}
+
+func (s *SamplingSuite) TestInterval() {
+	sampling := logger.NewSamplingOps(1, 0)
+	sampling.Interval = 20 * time.Millisecond
+	log := s.newLogger(sampling)
+
+	log.Info("tick")
+	log.Info("tick")
+	time.Sleep(30 * time.Millisecond)
+	log.Info("tick")
+
+	s.Equal(2, s.count("tick"))
+}
+
+func (s *SamplingSuite) TestLevelRatios() {
+	log := s.newLogger(&logger.SamplingOps{LevelRatios: map[slog.Level]float64{slog.LevelDebug: 0, slog.LevelInfo: 0.5}})
+
+	for i := 0; i < 1000; i++ {
+		log.Debug("debug")
+		log.Info("info")
+		log.Warn("warn")
+	}
+
+	s.Zero(s.count("debug"))
+	s.InDelta(500, s.count("info"), 100)
+	s.Equal(1000, s.count("warn"))
+}
+
+func (s *SamplingSuite) TestDedupe() {
+	log := s.newLogger(&logger.SamplingOps{Interval: 20 * time.Millisecond, Dedupe: true})
+	ctx := log.WithValue(context.Background(), slog.String("request_id", "r1"))
+
+	for i := 0; i < 5; i++ {
+		log.WithGroup("g").ErrorContext(ctx, "connection refused", "attempt", i)
+	}
+	log.Error("other")
+
+	records := s.buf.records()
+	s.Len(records, 2)
+	s.NotContains(records[0], logger.RepeatedKey)
+
+	s.Eventually(func() bool { return len(s.buf.records()) == 3 }, time.Second, 5*time.Millisecond)
+	summary := s.buf.records()[2]
+	s.Equal("connection refused", summary["msg"])
+	s.Equal(float64(4), summary[logger.RepeatedKey])
+	s.Equal("r1", summary["request_id"])
+	s.Equal(map[string]any{"attempt": float64(4)}, summary["g"])
+
+	// A new interval starts with the next record
+	log.Error("connection refused")
+	s.Equal(4, len(s.buf.records()))
+}
+
+func (s *SamplingSuite) TestDedupe_Close() {
+	log := s.newLogger(&logger.SamplingOps{Interval: time.Hour, Dedupe: true})
+
+	for i := 0; i < 3; i++ {
+		log.Error("connection refused")
+	}
+	s.Len(s.buf.records(), 1)
+
+	s.NoError(log.Close(context.Background()))
+	records := s.buf.records()
+	s.Len(records, 2)
+	s.Equal(float64(2), records[1][logger.RepeatedKey])
+}
+
+func (s *SamplingSuite) TestConcurrent() {
+	sampling := logger.NewSamplingOps(10, 10)
+	sampling.Interval = time.Hour
+	sampling.Dedupe = true
+	log := s.newLogger(sampling)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 10 first ones then every 10th of the remaining 790, all but the first collapsed by dedupe
+	s.Equal(1, s.count("concurrent"))
+}
+
+func TestSamplingSuite(t *testing.T) {
+	suite.Run(t, new(SamplingSuite))
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+const maxSourceFrames = 64
+
+var (
+	helpers sync.Map // function name -> struct{}
+
+	buildInfoOnce sync.Once
+	mainModule    string
+	mainPackage   string
+)
+
+// Helper marks the calling function as a logging helper, like testing.T.Helper.
+// Records logged from a helper report the source of its caller instead,
+// so wrappers around the logger don't all point to the same line.
+func Helper() {
+	var pc [1]uintptr
+	if runtime.Callers(2, pc[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	if frame.Function != "" {
+		helpers.Store(frame.Function, struct{}{})
+	}
+}
+
+func isHelper(function string) bool {
+	_, ok := helpers.Load(function)
+	return ok
+}
+
+// callerFrame resolves the frame of pc, the call site recorded by slog, skipping helper frames.
+// Helpers can only be skipped when the record is handled on the goroutine that logged it,
+// otherwise the frame of pc is used as is.
+func callerFrame(pc uintptr) (runtime.Frame, bool) {
+	if pc == 0 {
+		return runtime.Frame{}, false
+	}
+
+	pcs := []uintptr{pc}
+	var stack [maxSourceFrames]uintptr
+	n := runtime.Callers(2, stack[:])
+	for i, p := range stack[:n] {
+		if p == pc {
+			pcs = stack[i:n]
+			break
+		}
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	var first runtime.Frame
+	for {
+		frame, more := frames.Next()
+		if first.PC == 0 {
+			first = frame
+		}
+		if !isHelper(frame.Function) {
+			return frame, true
+		}
+		if !more {
+			// Only helpers were found, report the outermost call site we know of
+			return first, true
+		}
+	}
+}
+
+// formatSource formats the frame as "file:line", optionally followed by the function name.
+func (h *ctxLoggerHandler) formatSource(frame runtime.Frame) string {
+	file := frame.File
+	switch {
+	case h.ShortSource:
+		file = filepath.Base(file)
+	case h.ModuleSource:
+		file = moduleRelativeFile(frame)
+	}
+
+	if h.SourceFunction && frame.Function != "" {
+		return fmt.Sprintf("%s:%d %s", file, frame.Line, shortFunction(frame.Function))
+	}
+	return fmt.Sprintf("%s:%d", file, frame.Line)
+}
+
+// moduleRelativeFile returns the file path relative to the main module root, derived from the
+// package path of the function so it also works for binaries built with -trimpath.
+// Files of other modules keep their full package path.
+func moduleRelativeFile(frame runtime.Frame) string {
+	pkg := packagePath(frame.Function)
+	if pkg == "" {
+		return frame.File
+	}
+
+	buildInfoOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			mainModule, mainPackage = info.Main.Path, info.Path
+		}
+	})
+	if pkg == "main" {
+		if mainPackage == "" {
+			return frame.File
+		}
+		pkg = mainPackage
+	}
+
+	// External test packages live in the directory of the package they test
+	file := strings.TrimSuffix(pkg, "_test") + "/" + filepath.Base(frame.File)
+	if mainModule != "" {
+		if rel, ok := strings.CutPrefix(file, mainModule+"/"); ok {
+			return rel
+		}
+	}
+	return file
+}
+
+// packagePath extracts the package path from a fully qualified function name,
+// such as "github.com/umefy/godash/logger.(*Logger).Info". The linker escapes
+// dots in the last path element ("gopkg.in/yaml%2ev3.Marshal"), so the first
+// dot after the last slash always ends the path and the escapes are undone after.
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return unescapeSymbol(function[:slash+1+dot])
+}
+
+// shortFunction strips the package directory from a function name, keeping "logger.(*Logger).Info".
+func shortFunction(function string) string {
+	return unescapeSymbol(function[strings.LastIndex(function, "/")+1:])
+}
+
+// unescapeSymbol undoes the linker's %xx escaping of import paths in symbol names.
+func unescapeSymbol(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	if unescaped, err := url.PathUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+type SourceSuite struct {
+	suite.Suite
+}
+
+func (s *SourceSuite) source(opts *logger.LoggerOps, logFn func(log *logger.Logger)) string {
+	var buf bytes.Buffer
+	opts.Writer = &buf
+	logFn(logger.New(opts, nil))
+
+	var record map[string]any
+	s.Require().Nil(json.Unmarshal(buf.Bytes(), &record))
+	return record["source"].(string)
+}
+
+func (s *SourceSuite) line() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line + 1)
+}
+
+func logDirectly(log *logger.Logger) {
+	log.Info("direct")
+}
+
+func logThroughHelper(log *logger.Logger) {
+	logger.Helper()
+	log.Info("helper")
+}
+
+func logThroughNestedHelper(log *logger.Logger) {
+	logger.Helper()
+	logThroughHelper(log)
+}
+
+func (s *SourceSuite) TestSource_Formats() {
+	cases := []struct {
+		configure func(opts *logger.LoggerOps)
+		expected  func(line string) string
+	}{
+		{
+			func(opts *logger.LoggerOps) { opts.ShortSource = true },
+			func(line string) string { return "source_test.go:" + line },
+		},
+		{
+			func(opts *logger.LoggerOps) { opts.ModuleSource = true },
+			func(line string) string { return "logger/source_test.go:" + line },
+		},
+	}
+
+	for _, c := range cases {
+		opts := logger.NewLoggerOps(true, nil, slog.LevelInfo, true, "", 0)
+		c.configure(opts)
+
+		var line string
+		source := s.source(opts, func(log *logger.Logger) {
+			line = s.line()
+			log.Info("formats")
+		})
+
+		s.Equal(c.expected(line), source)
+	}
+}
+
+func (s *SourceSuite) TestSource_Function() {
+	opts := logger.NewLoggerOps(true, nil, slog.LevelInfo, true, "", 0)
+	opts.ShortSource, opts.SourceFunction = true, true
+
+	source := s.source(opts, logDirectly)
+
+	s.Regexp(`^source_test\.go:\d+ logger_test\.logDirectly$`, source)
+}
+
+func (s *SourceSuite) TestSource_FullPath() {
+	var line string
+	source := s.source(logger.NewLoggerOps(true, nil, slog.LevelInfo, true, "", 0), func(log *logger.Logger) {
+		line = s.line()
+		log.Info("full")
+	})
+
+	_, file, _, _ := runtime.Caller(0)
+	s.Equal(file+":"+line, source)
+}
+
+func (s *SourceSuite) TestSource_SkipsHelpers() {
+	opts := logger.NewLoggerOps(true, nil, slog.LevelInfo, true, "", 0)
+	opts.ShortSource = true
+
+	var line string
+	source := s.source(opts, func(log *logger.Logger) {
+		line = s.line()
+		logThroughHelper(log)
+	})
+	s.Equal("source_test.go:"+line, source)
+
+	source = s.source(opts, func(log *logger.Logger) {
+		line = s.line()
+		logThroughNestedHelper(log)
+	})
+	s.Equal("source_test.go:"+line, source)
+}
+
+func (s *SourceSuite) TestPackagePath() {
+	cases := map[string]string{
+		"github.com/umefy/godash/logger.(*Logger).Info":   "github.com/umefy/godash/logger",
+		"github.com/umefy/godash/logger_test.logDirectly": "github.com/umefy/godash/logger_test",
+		"github.com/umefy/godash/logger.New.func1":        "github.com/umefy/godash/logger",
+		"gopkg.in/yaml%2ev3.(*Decoder).Decode":            "gopkg.in/yaml.v3",
+		"gopkg.in/yaml%2ev3.Marshal":                      "gopkg.in/yaml.v3",
+		"main.main":                                       "main",
+		"noDot":                                           "",
+	}
+	for function, want := range cases {
+		s.Equal(want, logger.PackagePath(function), function)
+	}
+}
+
+func (s *SourceSuite) TestSource_DerivedLogger() {
+	opts := logger.NewLoggerOps(true, nil, slog.LevelInfo, true, "", 0)
+	opts.ShortSource = true
+
+	var line string
+	source := s.source(opts, func(log *logger.Logger) {
+		line = s.line()
+		log.With("k", "v").WithGroup("g").Info("derived")
+	})
+
+	s.Equal("source_test.go:"+line, source)
+}
+
+func TestSourceSuite(t *testing.T) {
+	suite.Run(t, new(SourceSuite))
+}
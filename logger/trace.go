@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader is the W3C Trace Context header.
+	TraceparentHeader = "traceparent"
+	// TraceIDKey and SpanIDKey are the attribute keys of trace correlation.
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+var ErrInvalidTraceparent = errors.New("logger: invalid traceparent")
+
+// TraceExtractor extracts the current trace and span IDs from a context,
+// reporting false when the context carries no trace.
+type TraceExtractor interface {
+	ExtractTrace(ctx context.Context) (traceID, spanID string, ok bool)
+}
+
+// TraceExtractorFunc adapts a function to TraceExtractor, for example with OpenTelemetry:
+//
+//	logger.TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.SpanID().String(), sc.IsValid()
+//	})
+type TraceExtractorFunc func(ctx context.Context) (traceID, spanID string, ok bool)
+
+func (f TraceExtractorFunc) ExtractTrace(ctx context.Context) (string, string, bool) {
+	return f(ctx)
+}
+
+// TraceContext is a parsed W3C traceparent.
+type TraceContext struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits, the parent ID of the header
+	Flags   byte
+}
+
+// Sampled reports whether the caller may have recorded the trace.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&0x01 != 0
+}
+
+// String formats the trace context as a version 00 traceparent.
+func (tc TraceContext) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+type traceContextKey struct{}
+
+// ParseTraceparent parses a W3C traceparent header value.
+// Headers of future versions are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(header string) (TraceContext, error) {
+	header = strings.TrimSpace(header)
+	if len(header) < 55 || header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return TraceContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, header)
+	}
+
+	version, traceID, spanID, flags := header[:2], header[3:35], header[36:52], header[53:55]
+	switch {
+	case !isLowerHex(version) || version == "ff",
+		version == "00" && len(header) != 55,
+		len(header) > 55 && header[55] != '-',
+		!isLowerHex(traceID) || strings.Trim(traceID, "0") == "",
+		!isLowerHex(spanID) || strings.Trim(spanID, "0") == "",
+		!isLowerHex(flags):
+		return TraceContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, header)
+	}
+
+	var flagsByte byte
+	_, _ = fmt.Sscanf(flags, "%02x", &flagsByte)
+	return TraceContext{TraceID: traceID, SpanID: spanID, Flags: flagsByte}, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ContextWithTraceContext returns a copy of parent carrying tc, extracted by TraceparentExtractor.
+func ContextWithTraceContext(parent context.Context, tc TraceContext) context.Context {
+	return context.WithValue(parent, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context stored with ContextWithTraceContext.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// TraceparentExtractor extracts the trace context stored by ContextWithTraceContext or TraceparentMiddleware,
+// for services without an OpenTelemetry SDK.
+var TraceparentExtractor TraceExtractor = TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+	tc, ok := TraceContextFromContext(ctx)
+	return tc.TraceID, tc.SpanID, ok
+})
+
+// TraceparentMiddleware parses the traceparent header of requests into their context.
+// Requests with a missing or invalid header are passed through unchanged.
+func TraceparentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tc, err := ParseTraceparent(r.Header.Get(TraceparentHeader)); err == nil {
+			r = r.WithContext(ContextWithTraceContext(r.Context(), tc))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *ctxLoggerHandler) traceAttrs(ctx context.Context) []slog.Attr {
+	if h.TraceExtractor == nil {
+		return nil
+	}
+	traceID, spanID, ok := h.TraceExtractor.ExtractTrace(ctx)
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{slog.String(TraceIDKey, traceID), slog.String(SpanIDKey, spanID)}
+}
@@ -0,0 +1,120 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/umefy/godash/logger"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+type TraceSuite struct {
+	suite.Suite
+}
+
+func (s *TraceSuite) TestParseTraceparent() {
+	tc, err := logger.ParseTraceparent(traceparent)
+
+	s.Nil(err)
+	s.Equal(logger.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: 1}, tc)
+	s.True(tc.Sampled())
+	s.Equal(traceparent, tc.String())
+}
+
+func (s *TraceSuite) TestParseTraceparent_FutureVersion() {
+	tc, err := logger.ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-what-the-future-holds")
+
+	s.Nil(err)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceID)
+	s.False(tc.Sampled())
+}
+
+func (s *TraceSuite) TestParseTraceparent_Invalid() {
+	cases := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x",
+	}
+
+	for _, c := range cases {
+		_, err := logger.ParseTraceparent(c)
+
+		s.ErrorIs(err, logger.ErrInvalidTraceparent, c)
+	}
+}
+
+func (s *TraceSuite) newLogger(buf *bytes.Buffer, extractor logger.TraceExtractor) *logger.Logger {
+	opts := logger.NewLoggerOps(true, buf, slog.LevelInfo, false, "", 0)
+	opts.TraceExtractor = extractor
+	return logger.New(opts, nil)
+}
+
+func (s *TraceSuite) record(buf *bytes.Buffer) map[string]any {
+	var record map[string]any
+	s.Require().Nil(json.Unmarshal(buf.Bytes(), &record))
+	buf.Reset()
+	return record
+}
+
+func (s *TraceSuite) TestTraceparentExtractor() {
+	var buf bytes.Buffer
+	log := s.newLogger(&buf, logger.TraceparentExtractor)
+	h := logger.TraceparentMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.WithGroup("g").InfoContext(r.Context(), "traced", "k", "v")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logger.TraceparentHeader, traceparent)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := s.record(&buf)
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", record[logger.TraceIDKey])
+	s.Equal("00f067aa0ba902b7", record[logger.SpanIDKey])
+	s.Equal(map[string]any{"k": "v"}, record["g"])
+
+	// Requests without a valid traceparent are logged without trace
+	req.Header.Set(logger.TraceparentHeader, "invalid")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	record = s.record(&buf)
+	s.NotContains(record, logger.TraceIDKey)
+	s.NotContains(record, logger.SpanIDKey)
+}
+
+func (s *TraceSuite) TestTraceExtractorFunc() {
+	type spanKey struct{}
+	var buf bytes.Buffer
+	log := s.newLogger(&buf, logger.TraceExtractorFunc(func(ctx context.Context) (string, string, bool) {
+		span, ok := ctx.Value(spanKey{}).(string)
+		return "trace-1", span, ok
+	}))
+
+	ctx := log.WithValue(context.WithValue(context.Background(), spanKey{}, "span-1"), slog.String("request_id", "r1"))
+	log.InfoContext(ctx, "traced")
+
+	record := s.record(&buf)
+	s.Equal("trace-1", record[logger.TraceIDKey])
+	s.Equal("span-1", record[logger.SpanIDKey])
+	s.Equal("r1", record["request_id"])
+
+	log.Info("untraced")
+	s.NotContains(s.record(&buf), logger.TraceIDKey)
+}
+
+func TestTraceSuite(t *testing.T) {
+	suite.Run(t, new(TraceSuite))
+}